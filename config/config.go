@@ -3,23 +3,62 @@ package config
 import (
 	"fmt"
 	"github.com/spf13/viper"
+	"time"
 )
 
 const (
 	defaultPort                = "3000"
 	defaultAppName             = "Boilerplate"
 	defaultDBDebugEnabledValue = "false"
+	defaultOmeAppName          = "app"
+	defaultPurgeInterval       = "1h"
+	defaultPurgeRetention      = "720h"
+	defaultOmePushStreamSuffix = "_rtmp"
+	defaultReconcileInterval   = "30s"
+	defaultPushRetryInterval   = "5s"
+	defaultIdempotencyTTL      = "24h"
+	defaultPlacementStrategy   = "least_loaded"
+	defaultNodeHealthInterval  = "10s"
+	defaultNodeDegradedLatency = "1s"
+	defaultNodeDownAfter       = 3
+	defaultStatsCacheTTL       = "5s"
+	defaultMetricsInterval     = "15s"
+	defaultMetricsRetention    = "168h"
+	defaultVodCatalogInterval  = "30s"
+	defaultVodStorage          = "local"
+	defaultVodStorageDir       = "./vod"
+	defaultSchedulerInterval   = "5s"
 )
 
 type (
 	Config struct {
-		PORT                string
-		APP_NAME            string
-		APP_URL             string
-		DB_DRIVER_NAME      string
-		OME_SERVER_BASE_URL string
-		DB_CONFIG           DB_CONFIG
-		MONGO_DB_CONFIG     MONGO_DB_CONFIG
+		PORT                   string
+		APP_NAME               string
+		APP_URL                string
+		DB_DRIVER_NAME         string
+		OME_SERVER_BASE_URL    string
+		OME_WEBHOOK_SECRET     string
+		OME_APP_NAME           string
+		OME_ALLOW_TAKEOVER     bool
+		OME_PUSH_SUFFIX        string
+		RECONCILE_INTERVAL     time.Duration
+		PUSH_RETRY_INTERVAL    time.Duration
+		IDEMPOTENCY_TTL        time.Duration
+		OME_PLACEMENT_STRATEGY string
+		NODE_HEALTH_INTERVAL   time.Duration
+		NODE_DEGRADED_LATENCY  time.Duration
+		NODE_DOWN_AFTER        int
+		STATS_CACHE_TTL        time.Duration
+		METRICS_INTERVAL       time.Duration
+		METRICS_RETENTION      time.Duration
+		VOD_CATALOG_INTERVAL   time.Duration
+		VOD_STORAGE            string
+		VOD_STORAGE_DIR        string
+		SCHEDULER_INTERVAL     time.Duration
+		PURGE_INTERVAL         time.Duration
+		PURGE_RETENTION        time.Duration
+		DB_CONFIG              DB_CONFIG
+		MONGO_DB_CONFIG        MONGO_DB_CONFIG
 	}
 	DB_CONFIG struct {
 		DB_NAME       string
@@ -60,6 +99,43 @@ func setFromEnv(config *Config) {
 	config.MONGO_DB_CONFIG.MONGODB_URL = viper.GetString("MONGODB_URL")
 	config.MONGO_DB_CONFIG.MONGODB_NAME = viper.GetString("MONGODB_NAME")
 	config.OME_SERVER_BASE_URL = viper.GetString("OME_SERVER_BASE_URL")
+	config.OME_WEBHOOK_SECRET = viper.GetString("OME_WEBHOOK_SECRET")
+	config.OME_APP_NAME = viper.GetString("OME_APP_NAME")
+	config.OME_ALLOW_TAKEOVER = viper.GetBool("OME_ALLOW_TAKEOVER")
+	config.OME_PUSH_SUFFIX = viper.GetString("OME_PUSH_SUFFIX")
+	config.RECONCILE_INTERVAL = getPositiveDuration("RECONCILE_INTERVAL", defaultReconcileInterval)
+	config.PUSH_RETRY_INTERVAL = getPositiveDuration("PUSH_RETRY_INTERVAL", defaultPushRetryInterval)
+	config.IDEMPOTENCY_TTL = getPositiveDuration("IDEMPOTENCY_TTL", defaultIdempotencyTTL)
+	config.OME_PLACEMENT_STRATEGY = viper.GetString("OME_PLACEMENT_STRATEGY")
+	config.NODE_HEALTH_INTERVAL = getPositiveDuration("NODE_HEALTH_INTERVAL", defaultNodeHealthInterval)
+	config.NODE_DEGRADED_LATENCY = getPositiveDuration("NODE_DEGRADED_LATENCY", defaultNodeDegradedLatency)
+	config.NODE_DOWN_AFTER = viper.GetInt("NODE_DOWN_AFTER")
+	if config.NODE_DOWN_AFTER < 1 {
+		config.NODE_DOWN_AFTER = defaultNodeDownAfter
+	}
+	config.STATS_CACHE_TTL = getPositiveDuration("STATS_CACHE_TTL", defaultStatsCacheTTL)
+	config.METRICS_INTERVAL = getPositiveDuration("METRICS_INTERVAL", defaultMetricsInterval)
+	config.METRICS_RETENTION = getPositiveDuration("METRICS_RETENTION", defaultMetricsRetention)
+	config.VOD_CATALOG_INTERVAL = getPositiveDuration("VOD_CATALOG_INTERVAL", defaultVodCatalogInterval)
+	config.VOD_STORAGE = viper.GetString("VOD_STORAGE")
+	config.VOD_STORAGE_DIR = viper.GetString("VOD_STORAGE_DIR")
+	config.SCHEDULER_INTERVAL = getPositiveDuration("SCHEDULER_INTERVAL", defaultSchedulerInterval)
+	config.PURGE_INTERVAL = getPositiveDuration("PURGE_INTERVAL", defaultPurgeInterval)
+	config.PURGE_RETENTION = getPositiveDuration("PURGE_RETENTION", defaultPurgeRetention)
+}
+
+// getPositiveDuration returns the duration set for key, falling back to defaultValue when it is
+// missing, malformed or not positive. Intervals feed time.NewTicker, which panics on those.
+func getPositiveDuration(key string, defaultValue string) time.Duration {
+	value := viper.GetDuration(key)
+	if value > 0 {
+		return value
+	}
+	if viper.GetString(key) != "" {
+		fmt.Printf("Invalid %s %q, using default %s\n", key, viper.GetString(key), defaultValue)
+	}
+	fallback, _ := time.ParseDuration(defaultValue)
+	return fallback
 }
 
 func parseConfigFile(envFilePath, configName string) {
@@ -81,4 +157,22 @@ func populateDefault() {
 	viper.SetDefault("PORT", defaultPort)
 	viper.SetDefault("APP_NAME", defaultAppName)
 	viper.SetDefault("DEBUG_ENABLED", defaultDBDebugEnabledValue)
+	viper.SetDefault("OME_APP_NAME", defaultOmeAppName)
+	viper.SetDefault("PURGE_INTERVAL", defaultPurgeInterval)
+	viper.SetDefault("PURGE_RETENTION", defaultPurgeRetention)
+	viper.SetDefault("OME_PUSH_SUFFIX", defaultOmePushStreamSuffix)
+	viper.SetDefault("RECONCILE_INTERVAL", defaultReconcileInterval)
+	viper.SetDefault("PUSH_RETRY_INTERVAL", defaultPushRetryInterval)
+	viper.SetDefault("IDEMPOTENCY_TTL", defaultIdempotencyTTL)
+	viper.SetDefault("OME_PLACEMENT_STRATEGY", defaultPlacementStrategy)
+	viper.SetDefault("NODE_HEALTH_INTERVAL", defaultNodeHealthInterval)
+	viper.SetDefault("NODE_DEGRADED_LATENCY", defaultNodeDegradedLatency)
+	viper.SetDefault("NODE_DOWN_AFTER", defaultNodeDownAfter)
+	viper.SetDefault("STATS_CACHE_TTL", defaultStatsCacheTTL)
+	viper.SetDefault("METRICS_INTERVAL", defaultMetricsInterval)
+	viper.SetDefault("METRICS_RETENTION", defaultMetricsRetention)
+	viper.SetDefault("VOD_CATALOG_INTERVAL", defaultVodCatalogInterval)
+	viper.SetDefault("VOD_STORAGE", defaultVodStorage)
+	viper.SetDefault("VOD_STORAGE_DIR", defaultVodStorageDir)
+	viper.SetDefault("SCHEDULER_INTERVAL", defaultSchedulerInterval)
 }
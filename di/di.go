@@ -4,8 +4,10 @@ import (
 	_ "github.com/lib/pq" // <------------ here
 	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/controller"
 	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/worker"
 	"github.com/toufiq-austcse/go-api-boilerplate/pkg/db/providers/mongodb"
 	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/storage"
 	"go.uber.org/dig"
 )
 
@@ -17,6 +19,14 @@ func NewDiContainer() (*dig.Container, error) {
 		http_clients.NewOmeHTTPClient,
 		mongodb.New,
 		service.NewOmeService,
+		storage.New,
+		worker.NewPurgeWorker,
+		worker.NewPushReconciler,
+		worker.NewPushRetryWorker,
+		worker.NewNodeHealthMonitor,
+		worker.NewMetricsSampler,
+		worker.NewVodCatalogWorker,
+		worker.NewScheduler,
 	}
 	for _, provider := range providers {
 		if err := c.Provide(provider); err != nil {
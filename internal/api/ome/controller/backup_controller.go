@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// handleBackupWebhook admits the backup encoder of a stream and fails pushes over to it when the
+// primary isn't live. The publisher state of the primary is left untouched.
+func (controller *OmeController) handleBackupWebhook(c *gin.Context, payload model.OmeWebhookRequest, stream *model.Stream) {
+	if payload.Request.Status == "opening" {
+		serverIp, _, err := controller.omeService.ResolveOriginNode(c, c.Request.Header.Get("X-Forwarded-For"), c.RemoteIP())
+		if err != nil {
+			fmt.Println("Error resolving origin node:", err)
+		}
+
+		admission := controller.omeService.CheckBackupAdmission(stream, serverIp, payload.Request.Url)
+		if !admission.Allowed {
+			fmt.Printf("Backup publish denied for stream %s: %s\n", stream.Id.Hex(), admission.Reason)
+			c.JSON(http.StatusOK, admission)
+			return
+		}
+
+		streamUpdate := bson.M{"backup_status": "opening"}
+		if stream.ServerIpAddress == "" {
+			streamUpdate["server_ip_address"] = serverIp
+		}
+		updatedStream, err := controller.omeService.UpdateStreamByID(c, stream.Id, streamUpdate)
+		if err != nil || updatedStream == nil {
+			fmt.Println("Error updating backup status:", err)
+			c.JSON(http.StatusOK, model.OmeWebhookResponse{
+				Allowed: false,
+				Reason:  "internal error",
+			})
+			return
+		}
+
+		if updatedStream.Status != "opening" {
+			go controller.onBackupPublished(updatedStream)
+		}
+		c.JSON(http.StatusOK, admission)
+		return
+	}
+
+	err := controller.omeService.HandleBackupClosed(c, stream)
+	if err != nil {
+		fmt.Println("Error handling backup close:", err)
+	}
+	c.JSON(http.StatusOK, model.OmeWebhookResponse{
+		Allowed: true,
+	})
+}
+
+// onBackupPublished fails a stream over to its backup once OME has created the backup stream,
+// unless the primary came back in the meantime.
+func (controller *OmeController) onBackupPublished(stream *model.Stream) {
+	ctx, cancel := context.WithTimeout(context.Background(), streamPublishedTimeout)
+	defer cancel()
+
+	err := controller.omeService.WaitForIngest(ctx, stream, stream.BackupIngestName)
+	if err != nil {
+		fmt.Println("Error waiting for backup ingest:", err)
+		return
+	}
+
+	currentStream, err := controller.omeService.FindStreamById(ctx, stream.Id)
+	if err != nil || currentStream == nil {
+		fmt.Println("Error finding stream by ID:", err)
+		return
+	}
+	err = controller.omeService.HandlePrimaryClosed(ctx, currentStream)
+	if err != nil {
+		fmt.Println("Error failing over to backup ingest:", err)
+	}
+}
@@ -0,0 +1,272 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/api_response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ChannelRequest struct {
+	Title    string                  `json:"title" binding:"required"`
+	Region   string                  `json:"region"`
+	App      string                  `json:"app"`
+	Programs []ChannelProgramRequest `json:"programs" binding:"required,min=1,dive"`
+	Fallback []ChannelItemRequest    `json:"fallback" binding:"dive"`
+}
+
+type ChannelProgramRequest struct {
+	Name        string               `json:"name" binding:"required"`
+	ScheduledAt time.Time            `json:"scheduled_at" binding:"required"`
+	Repeat      bool                 `json:"repeat"`
+	Items       []ChannelItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// ChannelItemRequest references either a vod asset or a live stream. Items without a duration
+// play to the end.
+type ChannelItemRequest struct {
+	VodAssetId string `json:"vod_asset_id" binding:"required_without=StreamId,excluded_with=StreamId"`
+	StreamId   string `json:"stream_id"`
+	StartMs    int64  `json:"start_ms" binding:"gte=0"`
+	DurationMs *int64 `json:"duration_ms" binding:"omitempty,gte=-1"`
+}
+
+func (request ChannelRequest) toChannel() (*model.Channel, error) {
+	channel := &model.Channel{
+		Title:    request.Title,
+		App:      request.App,
+		Programs: make([]model.ChannelProgram, 0, len(request.Programs)),
+	}
+	for _, program := range request.Programs {
+		items, err := toChannelItems(program.Items)
+		if err != nil {
+			return nil, err
+		}
+		channel.Programs = append(channel.Programs, model.ChannelProgram{
+			Name:        program.Name,
+			ScheduledAt: program.ScheduledAt,
+			Repeat:      program.Repeat,
+			Items:       items,
+		})
+	}
+	fallback, err := toChannelItems(request.Fallback)
+	if err != nil {
+		return nil, err
+	}
+	channel.Fallback = fallback
+	return channel, nil
+}
+
+func toChannelItems(requests []ChannelItemRequest) ([]model.ChannelItem, error) {
+	items := make([]model.ChannelItem, 0, len(requests))
+	for _, request := range requests {
+		item := model.ChannelItem{
+			StartMs:    request.StartMs,
+			DurationMs: -1,
+		}
+		if request.DurationMs != nil {
+			item.DurationMs = *request.DurationMs
+		}
+		var err error
+		if request.VodAssetId != "" {
+			item.VodAssetId, err = primitive.ObjectIDFromHex(request.VodAssetId)
+		} else {
+			item.StreamId, err = primitive.ObjectIDFromHex(request.StreamId)
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (controller *OmeController) CreateChannel(c *gin.Context) {
+	var body ChannelRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	channel, err := body.toChannel()
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	createdChannel, err := controller.omeService.CreateChannel(c, channel, body.Region)
+	if errors.Is(err, service.ErrChannelSourceNotFound) || errors.Is(err, service.ErrChannelSourceUnplayable) {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid channel item",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if errors.Is(err, service.ErrNoNodeAvailable) {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusServiceUnavailable,
+			"Failed to place channel",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if err != nil {
+		fmt.Println("Error creating channel:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to create channel",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusCreated, "Channel created successfully", createdChannel)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) ListChannels(c *gin.Context) {
+	channels, err := controller.omeService.ListChannels(c)
+	if err != nil {
+		fmt.Println("Error listing channels:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to list channels",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Channels fetched successfully", channels)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) GetChannel(c *gin.Context) {
+	existingChannel, ok := controller.findChannelFromParam(c)
+	if !ok {
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Channel fetched successfully", existingChannel)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+// UpdateChannel replaces the title, programs and fallback of a channel. The app and node
+// a channel runs on can't be changed.
+func (controller *OmeController) UpdateChannel(c *gin.Context) {
+	var body ChannelRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	channel, err := body.toChannel()
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	existingChannel, ok := controller.findChannelFromParam(c)
+	if !ok {
+		return
+	}
+	existingChannel.Title = channel.Title
+	existingChannel.Programs = channel.Programs
+	existingChannel.Fallback = channel.Fallback
+
+	updatedChannel, err := controller.omeService.UpdateChannel(c, existingChannel)
+	if errors.Is(err, service.ErrChannelSourceNotFound) || errors.Is(err, service.ErrChannelSourceUnplayable) {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid channel item",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if err != nil {
+		fmt.Println("Error updating channel:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to update channel",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Channel updated successfully", updatedChannel)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) DeleteChannel(c *gin.Context) {
+	existingChannel, ok := controller.findChannelFromParam(c)
+	if !ok {
+		return
+	}
+
+	err := controller.omeService.DeleteChannel(c, existingChannel)
+	if err != nil {
+		fmt.Println("Error deleting channel:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to delete channel",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Channel deleted successfully", api_response.EmptyObj{})
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+// findChannelFromParam resolves the :id route param to a channel, writing an error response when it can't.
+func (controller *OmeController) findChannelFromParam(c *gin.Context) (*model.Channel, bool) {
+	objId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid channel ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return nil, false
+	}
+
+	existingChannel, err := controller.omeService.FindChannelById(c, objId)
+	if err != nil {
+		fmt.Println("Error finding channel by ID:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to find channel",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return nil, false
+	}
+	if existingChannel == nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Channel not found",
+			"Channel does not exist", "")
+		c.JSON(errResponse.Code, errResponse)
+		return nil, false
+	}
+	return existingChannel, true
+}
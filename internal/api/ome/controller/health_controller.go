@@ -4,27 +4,10 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
 	"net/http"
 )
 
-type OmeWebhookRequest struct {
-	Client struct {
-		Address   string `json:"address"`
-		Port      int    `json:"port"`
-		RealIP    string `json:"real_ip"`
-		UserAgent string `json:"user_agent"`
-	} `json:"client"`
-	Request struct {
-		Direction string `json:"direction"`
-		NewUrl    string `json:"new_url"`
-		Protocol  string `json:"protocol"`
-		Status    string `json:"status"` // "opening", "closing"
-		Time      string `json:"time"`
-		Url       string `json:"url"` // We extract name from here
-
-	} `json:"request"`
-}
-
 // Index hosts godoc
 // @Summary  Health Check
 // @Tags     Index
@@ -50,7 +33,7 @@ func Webhook() gin.HandlerFunc {
 			}
 		}
 
-		var payload OmeWebhookRequest
+		var payload model.OmeWebhookRequest
 
 		err := context.BindJSON(&payload)
 		if err != nil {
@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/api_response"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CreateNodeRequest struct {
+	Name      string                `json:"name" binding:"required"`
+	Region    string                `json:"region"`
+	IpAddress string                `json:"ip_address" binding:"required,ip"`
+	IngestUrl string                `json:"ingest_url" binding:"required,url"`
+	ApiUrl    string                `json:"api_url" binding:"required,url"`
+	ApiToken  string                `json:"api_token"`
+	Capacity  int                   `json:"capacity" binding:"gte=0"`
+	Endpoints *NodeEndpointsRequest `json:"endpoints"`
+}
+
+type NodeEndpointsRequest struct {
+	Hostname        string `json:"hostname" binding:"omitempty,hostname_rfc1123"`
+	BasePath        string `json:"base_path" binding:"omitempty,startswith=/"`
+	Tls             *bool  `json:"tls"`
+	WebRtcPort      int    `json:"webrtc_port" binding:"omitempty,min=1,max=65535"`
+	HttpPort        int    `json:"http_port" binding:"omitempty,min=1,max=65535"`
+	RtmpPort        int    `json:"rtmp_port" binding:"omitempty,min=1,max=65535"`
+	SrtIngestPort   int    `json:"srt_ingest_port" binding:"omitempty,min=1,max=65535"`
+	SrtPlaybackPort int    `json:"srt_playback_port" binding:"omitempty,min=1,max=65535"`
+}
+
+func (request *NodeEndpointsRequest) toEndpoints() model.NodeEndpoints {
+	if request == nil {
+		return model.NodeEndpoints{}
+	}
+	return model.NodeEndpoints{
+		Hostname:        request.Hostname,
+		BasePath:        request.BasePath,
+		Tls:             request.Tls,
+		WebRtcPort:      request.WebRtcPort,
+		HttpPort:        request.HttpPort,
+		RtmpPort:        request.RtmpPort,
+		SrtIngestPort:   request.SrtIngestPort,
+		SrtPlaybackPort: request.SrtPlaybackPort,
+	}
+}
+
+type UpdateNodeRequest struct {
+	Name      *string `json:"name"`
+	Region    *string `json:"region"`
+	IngestUrl *string `json:"ingest_url" binding:"omitempty,url"`
+	ApiUrl    *string `json:"api_url" binding:"omitempty,url"`
+	ApiToken  *string `json:"api_token"`
+	Capacity  *int    `json:"capacity" binding:"omitempty,gte=0"`
+	// Endpoints replaces the whole endpoint configuration when set.
+	Endpoints *NodeEndpointsRequest `json:"endpoints"`
+}
+
+func (controller *OmeController) CreateNode(c *gin.Context) {
+	var body CreateNodeRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	node, err := controller.omeService.CreateNode(c, &model.OmeNode{
+		Name:      body.Name,
+		Region:    body.Region,
+		IpAddress: body.IpAddress,
+		IngestUrl: body.IngestUrl,
+		ApiUrl:    body.ApiUrl,
+		ApiToken:  body.ApiToken,
+		Capacity:  body.Capacity,
+		Endpoints: body.Endpoints.toEndpoints(),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusConflict,
+			"Node already exists",
+			"A node with this ip_address is already registered", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if err != nil {
+		fmt.Println("Error creating node:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to create node",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusCreated, "Node created successfully", node)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) ListNodes(c *gin.Context) {
+	nodes, err := controller.omeService.ListNodes(c)
+	if err != nil {
+		fmt.Println("Error listing nodes:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to list nodes",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Nodes fetched successfully", nodes)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) GetNode(c *gin.Context) {
+	objId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid node ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	node, err := controller.omeService.FindNodeById(c, objId)
+	if err != nil {
+		fmt.Println("Error finding node by ID:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to find node",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if node == nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Node not found",
+			"Node does not exist", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Node fetched successfully", node)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) UpdateNode(c *gin.Context) {
+	objId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid node ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	var body UpdateNodeRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	update := bson.M{}
+	if body.Name != nil {
+		update["name"] = *body.Name
+	}
+	if body.Region != nil {
+		update["region"] = *body.Region
+	}
+	if body.IngestUrl != nil {
+		update["ingest_url"] = *body.IngestUrl
+	}
+	if body.ApiUrl != nil {
+		update["api_url"] = *body.ApiUrl
+	}
+	if body.ApiToken != nil {
+		update["api_token"] = *body.ApiToken
+	}
+	if body.Capacity != nil {
+		update["capacity"] = *body.Capacity
+	}
+	if body.Endpoints != nil {
+		update["endpoints"] = body.Endpoints.toEndpoints()
+	}
+
+	node, err := controller.omeService.UpdateNodeByID(c, objId, update)
+	if err != nil {
+		fmt.Println("Error updating node:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to update node",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if node == nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Node not found",
+			"Node does not exist", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Node updated successfully", node)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) DeleteNode(c *gin.Context) {
+	objId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid node ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	deleted, err := controller.omeService.DeleteNode(c, objId)
+	if err != nil {
+		fmt.Println("Error deleting node:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to delete node",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if !deleted {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Node not found",
+			"Node does not exist", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Node deleted successfully", api_response.EmptyObj{})
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
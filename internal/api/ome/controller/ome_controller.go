@@ -1,8 +1,12 @@
 package controller
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/toufiq-austcse/go-api-boilerplate/config"
@@ -14,15 +18,26 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const streamPublishedTimeout = 30 * time.Second
+
 type StartPushRequest struct {
 	StreamID string `json:"stream_id" binding:"required"`
-	RtmpUrl  string `json:"rtmp_url" binding:"required"`
+	PushTargetRequest
 }
 type StopPushRequest struct {
 	StreamID string `json:"stream_id" binding:"required"`
+	PushID   string `json:"push_id"`
 }
 type CreateStreamRequest struct {
-	ExternalId string `json:"external_id" binding:"required"`
+	ExternalId   string `json:"external_id" binding:"required"`
+	Region       string `json:"region"`
+	App          string `json:"app"`
+	MaxSessionMs int64  `json:"max_session_ms" binding:"gte=0"`
+	ViewerPolicy string `json:"viewer_policy" binding:"omitempty,oneof=public token disabled"`
+	ViewerToken  string `json:"viewer_token" binding:"required_if=ViewerPolicy token"`
+	AutoRecord   bool   `json:"auto_record"`
+	// BackupIngestName is the stream name a backup encoder publishes to.
+	BackupIngestName string `json:"backup_ingest_name" binding:"omitempty,max=64,excludesall=/?#&"`
 }
 type OmeController struct {
 	omeService    *service.OmeService
@@ -37,64 +52,211 @@ func NewOmeController(omeService *service.OmeService, omeHttpClient *http_client
 }
 
 func (controller *OmeController) Webhook(c *gin.Context) {
-	// Print headers
-	fmt.Println("=== Webhook Request Headers ===")
-	for key, values := range c.Request.Header {
-		for _, value := range values {
-			fmt.Printf("%s: %s\n", key, value)
-		}
+	rawBody, err := c.GetRawData()
+	if err != nil {
+		fmt.Println("Error reading webhook body:", err)
+		c.JSON(http.StatusOK, model.OmeWebhookResponse{
+			Allowed: false,
+			Reason:  "invalid request body",
+		})
+		return
+	}
+
+	signature := c.GetHeader("X-OME-Signature")
+	if !controller.omeService.VerifyWebhookSignature(rawBody, signature, config.AppConfig.OME_WEBHOOK_SECRET) {
+		fmt.Printf("[audit] rejected webhook with invalid signature: remote_ip=%s forwarded_for=%s signature_present=%t\n",
+			c.ClientIP(), c.GetHeader("X-Forwarded-For"), signature != "")
+		c.JSON(http.StatusOK, model.OmeWebhookResponse{
+			Allowed: false,
+			Reason:  "invalid signature",
+		})
+		return
 	}
 
-	var payload OmeWebhookRequest
+	var payload model.OmeWebhookRequest
 
-	err := c.BindJSON(&payload)
+	err = json.Unmarshal(rawBody, &payload)
 	if err != nil {
 		fmt.Println("Error parsing JSON payload:", err)
+		c.JSON(http.StatusOK, model.OmeWebhookResponse{
+			Allowed: false,
+			Reason:  "invalid request body",
+		})
 		return
 	}
 
-	// Print the parsed payload
-	fmt.Println("=== Webhook Request Body (Parsed) ===")
-	fmt.Printf("%+v\n", payload)
-	fmt.Println("==============================")
-
 	streamName := controller.omeService.GetStreamName(payload.Request.Url)
-	objId, err := primitive.ObjectIDFromHex(streamName)
-	if err != nil {
-		fmt.Println("Error getting stream by name:", err)
+	fmt.Printf("Webhook request: %s %s for stream %s\n", payload.Request.Direction, payload.Request.Status, streamName)
+	if payload.Request.Direction == "outgoing" {
+		controller.handlePlaybackWebhook(c, payload, streamName)
 		return
 	}
+
+	if !primitive.IsValidObjectID(streamName) {
+		backupStream, err := controller.omeService.FindStreamByBackupIngestName(c, streamName)
+		if err != nil {
+			fmt.Println("Error finding stream by backup ingest name:", err)
+		}
+		if backupStream != nil {
+			controller.handleBackupWebhook(c, payload, backupStream)
+			return
+		}
+	}
+
 	if payload.Request.Status == "opening" {
+		stream, admission, err := controller.omeService.CheckPublishAdmission(c, streamName, payload.Request.Url)
+		if err != nil {
+			fmt.Println("Error checking publish admission:", err)
+		}
+		if !admission.Allowed {
+			fmt.Printf("Publish denied for stream %s: %s\n", streamName, admission.Reason)
+			c.JSON(http.StatusOK, admission)
+			return
+		}
+
+		serverIp, node, err := controller.omeService.ResolveOriginNode(c, c.Request.Header.Get("X-Forwarded-For"), c.RemoteIP())
+		if err != nil {
+			fmt.Println("Error resolving origin node:", err)
+		}
 
-		err := controller.omeHttpClient.DeleteStream(streamName, c.Request.Header.Get("X-Forwarded-For"))
+		err = controller.omeHttpClient.DeleteStream(streamName, serverIp, controller.omeService.GetStreamApp(stream))
 		if err != nil {
 			fmt.Println("Error deleting existing stream:", err)
 
-			c.JSON(http.StatusOK, gin.H{
-				"allowed": false,
+			c.JSON(http.StatusOK, model.OmeWebhookResponse{
+				Allowed: false,
+				Reason:  "failed to release previous session",
 			})
-
+			return
 		}
 
-		_, err = controller.omeService.UpdateStreamByID(c, objId, bson.M{
+		streamUpdate := bson.M{
 			"status":            payload.Request.Status,
 			"protocol":          payload.Request.Protocol,
-			"server_ip_address": c.Request.Header.Get("X-Forwarded-For"),
-		})
+			"server_ip_address": serverIp,
+		}
+		if node != nil {
+			streamUpdate["node_id"] = node.Id
+		}
+		_, err = controller.omeService.UpdateStreamByID(c, stream.Id, streamUpdate)
 		if err != nil {
+			fmt.Println("Error updating stream status:", err)
+			c.JSON(http.StatusOK, model.OmeWebhookResponse{
+				Allowed: false,
+				Reason:  "internal error",
+			})
 			return
 		}
-	} else {
-		_, err = controller.omeService.UpdateStreamByID(c, objId, bson.M{
-			"status": payload.Request.Status,
+
+		session, err := controller.omeService.OpenStreamSession(c, &model.StreamSession{
+			StreamId:        stream.Id,
+			Protocol:        payload.Request.Protocol,
+			ServerIpAddress: serverIp,
+			ClientAddress:   payload.Client.Address,
+			ClientPort:      payload.Client.Port,
+			RealIp:          payload.Client.RealIP,
+			UserAgent:       payload.Client.UserAgent,
 		})
 		if err != nil {
+			fmt.Println("Error opening stream session:", err)
+		} else {
+			publishedStream := *stream
+			publishedStream.Status = "opening"
+			publishedStream.ServerIpAddress = serverIp
+			go controller.onStreamPublished(&publishedStream, session.Id)
+		}
+		c.JSON(http.StatusOK, admission)
+		return
+	}
+
+	objId, err := primitive.ObjectIDFromHex(streamName)
+	if err != nil {
+		fmt.Println("Error getting stream by name:", err)
+		c.JSON(http.StatusOK, model.OmeWebhookResponse{
+			Allowed: false,
+			Reason:  "unknown stream",
+		})
+		return
+	}
+
+	// After a takeover the replaced publisher's closing event can arrive once the new publisher
+	// is live, it must not close the new session.
+	openSession, err := controller.omeService.FindOpenStreamSession(c, objId)
+	if err != nil {
+		fmt.Println("Error finding open stream session:", err)
+	}
+	if openSession != nil && !openSession.IsPublishedBy(payload.Client.Address, payload.Client.Port) {
+		fmt.Printf("Ignoring closing event of replaced publisher %s:%d on stream %s\n",
+			payload.Client.Address, payload.Client.Port, streamName)
+		c.JSON(http.StatusOK, model.OmeWebhookResponse{
+			Allowed: true,
+		})
+		return
+	}
+
+	err = controller.omeService.UpdateLiveStreamStatus(c, objId, payload.Request.Status)
+	if err != nil {
+		fmt.Println("Error updating stream status:", err)
+	}
+	err = controller.omeService.CloseStreamSession(c, objId)
+	if err != nil {
+		fmt.Println("Error closing stream session:", err)
+	}
+	go controller.onStreamClosed(objId)
+	closedStream, err := controller.omeService.FindStreamById(c, objId)
+	if err != nil {
+		fmt.Println("Error finding stream by ID:", err)
+	}
+	if closedStream != nil {
+		err = controller.omeService.HandlePrimaryClosed(c, closedStream)
+		if err != nil {
+			fmt.Println("Error failing over to backup ingest:", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, model.OmeWebhookResponse{
+		Allowed: true,
+	})
+}
+
+// handlePlaybackWebhook admits viewers and records their sessions without touching
+// the publisher state of the stream.
+func (controller *OmeController) handlePlaybackWebhook(c *gin.Context, payload model.OmeWebhookRequest, streamName string) {
+	if payload.Request.Status == "opening" {
+		stream, admission, err := controller.omeService.CheckPlaybackAdmission(c, streamName, payload.Request.Url)
+		if err != nil {
+			fmt.Println("Error checking playback admission:", err)
+		}
+		if !admission.Allowed {
+			fmt.Printf("Playback denied for stream %s: %s\n", streamName, admission.Reason)
+			c.JSON(http.StatusOK, admission)
 			return
 		}
+
+		_, err = controller.omeService.CreateViewerSession(c, &model.ViewerSession{
+			StreamId:      stream.Id,
+			Protocol:      payload.Request.Protocol,
+			ClientAddress: payload.Client.Address,
+			ClientPort:    payload.Client.Port,
+			RealIp:        payload.Client.RealIP,
+			UserAgent:     payload.Client.UserAgent,
+		})
+		if err != nil {
+			fmt.Println("Error creating viewer session:", err)
+		}
+		c.JSON(http.StatusOK, admission)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"allowed": true,
+	objId, err := primitive.ObjectIDFromHex(streamName)
+	if err == nil {
+		err = controller.omeService.CloseViewerSession(c, objId, payload.Client.Address, payload.Client.Port)
+		if err != nil {
+			fmt.Println("Error closing viewer session:", err)
+		}
+	}
+	c.JSON(http.StatusOK, model.OmeWebhookResponse{
+		Allowed: true,
 	})
 }
 
@@ -126,10 +288,60 @@ func (controller *OmeController) CreateStream(c *gin.Context) {
 	if existingStream != nil {
 		resultStream = existingStream
 	} else {
-		stream, err := controller.omeService.CreateStream(c, &model.Stream{
-			Status:     "initiated",
+		viewerPolicy := body.ViewerPolicy
+		if viewerPolicy == "" {
+			viewerPolicy = "public"
+		}
+		node, err := controller.omeService.PlaceStream(c, service.PlacementRequest{
 			ExternalId: body.ExternalId,
+			Region:     body.Region,
 		})
+		if err != nil {
+			fmt.Println("Error placing stream:", err)
+			errResponse := api_response.BuildErrorResponse(
+				http.StatusServiceUnavailable,
+				"Failed to place stream",
+				err.Error(), "")
+			c.JSON(errResponse.Code, errResponse)
+			return
+		}
+
+		if body.BackupIngestName != "" {
+			backupStream, err := controller.omeService.FindStreamByBackupIngestName(c, body.BackupIngestName)
+			if err != nil {
+				fmt.Println("Error checking backup ingest name:", err)
+				errResponse := api_response.BuildErrorResponse(
+					http.StatusInternalServerError,
+					"Failed to check backup ingest name",
+					err.Error(), "")
+				c.JSON(errResponse.Code, errResponse)
+				return
+			}
+			// Stream IDs are valid ingest names too, so a backup name must never look like one.
+			if backupStream != nil || primitive.IsValidObjectID(body.BackupIngestName) {
+				errResponse := api_response.BuildErrorResponse(
+					http.StatusConflict,
+					"Backup ingest name is not available",
+					"backup_ingest_name is already in use", "")
+				c.JSON(errResponse.Code, errResponse)
+				return
+			}
+		}
+
+		newStream := &model.Stream{
+			Status:           "initiated",
+			ExternalId:       body.ExternalId,
+			App:              body.App,
+			MaxSessionMs:     body.MaxSessionMs,
+			ViewerPolicy:     viewerPolicy,
+			ViewerToken:      body.ViewerToken,
+			AutoRecord:       body.AutoRecord,
+			BackupIngestName: body.BackupIngestName,
+		}
+		if node != nil {
+			newStream.NodeId = node.Id
+		}
+		stream, err := controller.omeService.CreateStream(c, newStream)
 		if err != nil {
 			fmt.Println("Error creating stream:", err)
 			errResponse := api_response.BuildErrorResponse(
@@ -142,54 +354,121 @@ func (controller *OmeController) CreateStream(c *gin.Context) {
 		resultStream = stream
 	}
 
-	whipUrl := fmt.Sprintf("%s/app/%s?direction=whip", config.AppConfig.OME_SERVER_BASE_URL, resultStream.Id.Hex())
-	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream created successfully", map[string]interface{}{
+	whipUrl, err := controller.omeService.GetWhipUrl(c, resultStream)
+	if err != nil {
+		fmt.Println("Error building whip url:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to build whip url",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	responseData := map[string]interface{}{
 		"_id":         resultStream.Id.Hex(),
 		"status":      resultStream.Status,
 		"external_id": resultStream.ExternalId,
 		"whip_url":    whipUrl,
 		"created_at":  resultStream.CreatedAt,
 		"updated_at":  resultStream.UpdatedAt,
-	})
+	}
+	if !resultStream.NodeId.IsZero() {
+		responseData["node_id"] = resultStream.NodeId.Hex()
+	}
+	if resultStream.BackupIngestName != "" {
+		backupWhipUrl, err := controller.omeService.GetBackupWhipUrl(c, resultStream)
+		if err != nil {
+			fmt.Println("Error building backup whip url:", err)
+			errResponse := api_response.BuildErrorResponse(
+				http.StatusInternalServerError,
+				"Failed to build backup whip url",
+				err.Error(), "")
+			c.JSON(errResponse.Code, errResponse)
+			return
+		}
+		responseData["backup_whip_url"] = backupWhipUrl
+	}
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream created successfully", responseData)
 	c.JSON(apiResponseBody.Code, apiResponseBody)
 
 }
 
+// StopStream forcibly ends a live broadcast: it stops all active pushes, kicks the
+// publisher off the origin and marks the stream ended so it can't be republished
+// until EnableStream is called.
 func (controller *OmeController) StopStream(c *gin.Context) {
-	//streamId := c.Param("id")
-	//objId, err := primitive.ObjectIDFromHex(streamId)
-	//if err != nil {
-	//	fmt.Println("Error getting stream by name:", err)
-	//	return
-	//}
-	//
-	//_, err = controller.omeService.FindByID(c, objId)
-	//if err != nil {
-	//	fmt.Println("Error finding stream by ID:", err)
-	//	return
-	//}
-	//
-	//c.JSON(http.StatusOK, gin.H{
-	//	"message": "Stream stopped successfully",
-	//})
+	objId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid stream ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
 
-}
+	existingStream, err := controller.omeService.FindStreamById(c, objId)
+	if err != nil {
+		fmt.Println("Error finding stream by ID:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to find stream",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if existingStream == nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Stream not found",
+			"Stream does not exist", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
 
-func (controller *OmeController) StartPush(c *gin.Context) {
-	var body StartPushRequest
-	if err := c.ShouldBindJSON(&body); err != nil {
-		fmt.Println("Error binding JSON:", err)
+	// Mark the stream ended first so the publisher can't be re-admitted while we tear it down.
+	_, err = controller.omeService.UpdateStreamByID(c, objId, bson.M{
+		"status": "ended",
+	})
+	if err != nil {
+		fmt.Println("Error updating stream status:", err)
 		errResponse := api_response.BuildErrorResponse(
-			http.StatusBadRequest,
-			"Invalid request body",
+			http.StatusInternalServerError,
+			"Failed to update stream status",
 			err.Error(), "")
 		c.JSON(errResponse.Code, errResponse)
 		return
 	}
 
-	objId, err := primitive.ObjectIDFromHex(body.StreamID)
+	stoppedPushes, err := controller.stopStreamOutput(c, existingStream)
+	if err != nil {
+		fmt.Println("Error stopping stream output:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to stop stream output",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	err = controller.omeService.CloseStreamSession(c, objId)
+	if err != nil {
+		fmt.Println("Error closing stream session:", err)
+	}
+
+	fmt.Printf("[audit] stream %s stopped, %d push(es) stopped\n", objId.Hex(), stoppedPushes)
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream stopped successfully", map[string]interface{}{
+		"_id":    objId.Hex(),
+		"status": "ended",
+	})
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+// DeleteStream stops all live output of a stream on OME and soft-deletes the stream and
+// its pushes. The purge worker removes them for good once the retention period passes.
+func (controller *OmeController) DeleteStream(c *gin.Context) {
+	objId, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
-		fmt.Println("Error getting stream by ID:", err)
 		errResponse := api_response.BuildErrorResponse(
 			http.StatusBadRequest,
 			"Invalid stream ID",
@@ -202,8 +481,8 @@ func (controller *OmeController) StartPush(c *gin.Context) {
 	if err != nil {
 		fmt.Println("Error finding stream by ID:", err)
 		errResponse := api_response.BuildErrorResponse(
-			http.StatusNotFound,
-			"Stream not found",
+			http.StatusInternalServerError,
+			"Failed to find stream",
 			err.Error(), "")
 		c.JSON(errResponse.Code, errResponse)
 		return
@@ -217,100 +496,147 @@ func (controller *OmeController) StartPush(c *gin.Context) {
 		return
 	}
 
-	existingActivePush, err := controller.omeService.FindPushByStreamIdAndStatus(c, objId, "active")
+	_, err = controller.omeService.UpdateStreamByID(c, objId, bson.M{
+		"status": "ended",
+	})
 	if err != nil {
-		fmt.Println("Error finding stream by ID:", err)
+		fmt.Println("Error updating stream status:", err)
 		errResponse := api_response.BuildErrorResponse(
-			http.StatusNotFound,
-			"Stream not found",
+			http.StatusInternalServerError,
+			"Failed to update stream status",
 			err.Error(), "")
 		c.JSON(errResponse.Code, errResponse)
 		return
 	}
-	if existingActivePush != nil {
-		err := controller.omeHttpClient.StopPush(existingStream.ServerIpAddress, existingActivePush.Id.Hex())
+
+	_, err = controller.stopStreamOutput(c, existingStream)
+	if err != nil {
+		fmt.Println("Error stopping stream output:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to stop stream output",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	err = controller.omeService.CloseStreamSession(c, objId)
+	if err != nil {
+		fmt.Println("Error closing stream session:", err)
+	}
+
+	err = controller.omeService.SoftDeleteStream(c, objId)
+	if err != nil {
+		fmt.Println("Error deleting stream:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to delete stream",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream deleted successfully", api_response.EmptyObj{})
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+// stopStreamOutput stops every active push of the stream and disconnects its publisher
+// from the origin. It returns the number of pushes stopped.
+func (controller *OmeController) stopStreamOutput(c *gin.Context, stream *model.Stream) (int, error) {
+	activePushes, err := controller.omeService.FindPushesByStreamIdAndStatus(c, stream.Id, "active")
+	if err != nil {
+		return 0, err
+	}
+	for i := range activePushes {
+		err := controller.omeService.StopPush(c, stream, &activePushes[i])
 		if err != nil {
-			fmt.Println("Error stopping existing push:", err)
-			errResponse := api_response.BuildErrorResponse(
-				http.StatusInternalServerError,
-				"Failed to stop existing push",
-				err.Error(), "")
-			c.JSON(errResponse.Code, errResponse)
-			return
+			return 0, err
 		}
-		_, err = controller.omeService.UpdatePushByID(
-			c,
-			existingActivePush.Id, bson.M{
-				"status": "inactive",
-			})
+	}
+
+	retryingPushes, err := controller.omeService.FindPushesByStreamIdAndStatus(c, stream.Id, "retrying")
+	if err != nil {
+		return 0, err
+	}
+	for i := range retryingPushes {
+		err := controller.omeService.StopPush(c, stream, &retryingPushes[i])
 		if err != nil {
-			fmt.Println("Error updating existing push status:", err)
-			errResponse := api_response.BuildErrorResponse(
-				http.StatusInternalServerError,
-				"Failed to update existing push status",
-				err.Error(), "")
-			c.JSON(errResponse.Code, errResponse)
-			return
+			return 0, err
 		}
 	}
 
-	createdPush, err := controller.omeService.CreatePush(c, &model.Push{
-		StreamId:        objId,
-		RtmpUrl:         body.RtmpUrl,
-		ServerIpAddress: existingStream.ServerIpAddress,
-		Status:          "pending",
-	})
+	activeRecordings, err := controller.omeService.FindRecordingsByStreamIdAndStates(c, stream.Id, []string{"pending", "ready", "recording"})
+	if err != nil {
+		return 0, err
+	}
+	for i := range activeRecordings {
+		_, err := controller.omeService.StopRecording(c, &activeRecordings[i])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if stream.ServerIpAddress != "" {
+		err = controller.omeHttpClient.DeleteStream(stream.Id.Hex(), stream.ServerIpAddress, controller.omeService.GetStreamApp(stream))
+		if err != nil {
+			return 0, err
+		}
+		if stream.BackupIngestName != "" {
+			err = controller.omeHttpClient.DeleteStream(stream.BackupIngestName, stream.ServerIpAddress, controller.omeService.GetStreamApp(stream))
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(activePushes), nil
+}
+
+// EnableStream re-enables an ended or disabled stream so it can be published again.
+func (controller *OmeController) EnableStream(c *gin.Context) {
+	objId, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
-		fmt.Println("Error creating push record:", err)
 		errResponse := api_response.BuildErrorResponse(
-			http.StatusInternalServerError,
-			"Failed to create push record",
+			http.StatusBadRequest,
+			"Invalid stream ID",
 			err.Error(), "")
 		c.JSON(errResponse.Code, errResponse)
 		return
 	}
 
-	_, err = controller.omeHttpClient.StartPush(
-		existingStream.ServerIpAddress,
-		existingStream.Id.Hex(),
-		body.RtmpUrl, createdPush.Id.Hex())
-	if err != nil {
-		fmt.Println("Error starting push:", err)
+	updatedStream, err := controller.omeService.EnableStream(c, objId)
+	if errors.Is(err, service.ErrStreamNotStopped) {
 		errResponse := api_response.BuildErrorResponse(
-			http.StatusInternalServerError,
-			"Failed to start push",
+			http.StatusConflict,
+			"Stream can't be enabled",
 			err.Error(), "")
 		c.JSON(errResponse.Code, errResponse)
 		return
 	}
-
-	_, err = controller.omeService.UpdatePushByID(
-		c,
-		createdPush.Id, bson.M{
-			"status": "active",
-		})
 	if err != nil {
-		fmt.Println("Error updating push status:", err)
+		fmt.Println("Error updating stream status:", err)
 		errResponse := api_response.BuildErrorResponse(
 			http.StatusInternalServerError,
-			"Failed to update push status",
+			"Failed to update stream status",
 			err.Error(), "")
 		c.JSON(errResponse.Code, errResponse)
 		return
 	}
+	if updatedStream == nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Stream not found",
+			"Stream does not exist", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":   "Push started successfully",
-		"push_id":   createdPush.Id.Hex(),
-		"rtmp_url":  body.RtmpUrl,
-		"server_ip": existingStream.ServerIpAddress,
-		"stream_id": body.StreamID,
-	})
-
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream enabled successfully", updatedStream)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
 }
 
-func (controller *OmeController) StopPush(c *gin.Context) {
-	var body StopPushRequest
+// StartPush adds a push target to the stream. Pushes already running for the stream are left untouched.
+func (controller *OmeController) StartPush(c *gin.Context) {
+	var body StartPushRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
 		fmt.Println("Error binding JSON:", err)
 		errResponse := api_response.BuildErrorResponse(
@@ -351,52 +677,209 @@ func (controller *OmeController) StopPush(c *gin.Context) {
 		return
 	}
 
-	existingActivePush, err := controller.omeService.FindPushByStreamIdAndStatus(c, objId, "active")
+	target := body.toPush()
+	err = controller.omeService.ValidatePushUrl(target.Protocol, target.Url)
 	if err != nil {
-		fmt.Println("Error finding active push by stream ID:", err)
 		errResponse := api_response.BuildErrorResponse(
-			http.StatusNotFound,
-			"Active push not found",
+			http.StatusBadRequest,
+			"Invalid push url",
 			err.Error(), "")
 		c.JSON(errResponse.Code, errResponse)
 		return
 	}
-	if existingActivePush == nil {
+
+	createdPush, err := controller.omeService.StartPush(c, existingStream, target)
+	if errors.Is(err, service.ErrDuplicatePushTarget) {
 		errResponse := api_response.BuildErrorResponse(
-			http.StatusNotFound,
-			"Active push not found",
-			"No active push for this stream", "")
+			http.StatusConflict,
+			"Push target already exists",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if errors.Is(err, service.ErrNodeDown) {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusServiceUnavailable,
+			"OME node is down",
+			err.Error(), "")
 		c.JSON(errResponse.Code, errResponse)
 		return
 	}
-	fmt.Println("existingActivePush ", existingActivePush)
-
-	err = controller.omeHttpClient.StopPush(existingStream.ServerIpAddress, existingActivePush.Id.Hex())
 	if err != nil {
-		fmt.Println("Error stopping push:", err)
+		fmt.Println("Error starting push:", err)
 		errResponse := api_response.BuildErrorResponse(
 			http.StatusInternalServerError,
-			"Failed to stop push",
+			"Failed to start push",
 			err.Error(), "")
 		c.JSON(errResponse.Code, errResponse)
 		return
 	}
-	_, err = controller.omeService.UpdatePushByID(
-		c,
-		existingActivePush.Id, bson.M{
-			"status": "inactive",
-		})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Push started successfully",
+		"push_id":   createdPush.Id.Hex(),
+		"protocol":  createdPush.Protocol,
+		"url":       createdPush.Url,
+		"rtmp_url":  createdPush.RtmpUrl,
+		"server_ip": existingStream.ServerIpAddress,
+		"stream_id": body.StreamID,
+	})
+
+}
+
+// StopPush stops the push given by push_id, or every active push of the stream when push_id is omitted.
+func (controller *OmeController) StopPush(c *gin.Context) {
+	var body StopPushRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		fmt.Println("Error binding JSON:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	objId, err := primitive.ObjectIDFromHex(body.StreamID)
 	if err != nil {
-		fmt.Println("Error updating push status:", err)
+		fmt.Println("Error getting stream by ID:", err)
 		errResponse := api_response.BuildErrorResponse(
-			http.StatusInternalServerError,
-			"Failed to update push status",
+			http.StatusBadRequest,
+			"Invalid stream ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	existingStream, err := controller.omeService.FindStreamById(c, objId)
+	if err != nil {
+		fmt.Println("Error finding stream by ID:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Stream not found",
 			err.Error(), "")
 		c.JSON(errResponse.Code, errResponse)
 		return
 	}
+	if existingStream == nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Stream not found",
+			"Stream does not exist", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	var activePushes []model.Push
+	if body.PushID != "" {
+		pushObjId, err := primitive.ObjectIDFromHex(body.PushID)
+		if err != nil {
+			errResponse := api_response.BuildErrorResponse(
+				http.StatusBadRequest,
+				"Invalid push ID",
+				err.Error(), "")
+			c.JSON(errResponse.Code, errResponse)
+			return
+		}
+		existingPush, err := controller.omeService.FindPushById(c, pushObjId)
+		if err != nil {
+			fmt.Println("Error finding push by ID:", err)
+			errResponse := api_response.BuildErrorResponse(
+				http.StatusInternalServerError,
+				"Failed to find push",
+				err.Error(), "")
+			c.JSON(errResponse.Code, errResponse)
+			return
+		}
+		// Pending and retrying pushes are stopped too, or the retry worker keeps restarting them.
+		if existingPush != nil && existingPush.StreamId == objId &&
+			(existingPush.Status == "active" || existingPush.Status == "pending" || existingPush.Status == "retrying") {
+			activePushes = append(activePushes, *existingPush)
+		}
+	} else {
+		for _, status := range []string{"active", "pending", "retrying"} {
+			pushes, err := controller.omeService.FindPushesByStreamIdAndStatus(c, objId, status)
+			if err != nil {
+				fmt.Println("Error finding active push by stream ID:", err)
+				errResponse := api_response.BuildErrorResponse(
+					http.StatusInternalServerError,
+					"Failed to find active push",
+					err.Error(), "")
+				c.JSON(errResponse.Code, errResponse)
+				return
+			}
+			activePushes = append(activePushes, pushes...)
+		}
+	}
+	if len(activePushes) == 0 {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Active push not found",
+			"No active push for this stream", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	for i := range activePushes {
+		err = controller.omeService.StopPush(c, existingStream, &activePushes[i])
+		if err != nil {
+			fmt.Println("Error stopping push:", err)
+			errResponse := api_response.BuildErrorResponse(
+				http.StatusInternalServerError,
+				"Failed to stop push",
+				err.Error(), "")
+			c.JSON(errResponse.Code, errResponse)
+			return
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Push stopped successfully",
 	})
 
 }
+
+// onStreamClosed syncs the recordings of a stream whose publisher left, so OME's slow records
+// call doesn't hold up the webhook response.
+func (controller *OmeController) onStreamClosed(streamId primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(context.Background(), streamPublishedTimeout)
+	defer cancel()
+
+	err := controller.omeService.SyncStreamRecordings(ctx, streamId)
+	if err != nil {
+		fmt.Println("Error syncing stream recordings:", err)
+	}
+}
+
+// onStreamPublished runs once OME has created a newly published stream: it records what the
+// publisher is sending on its session, moves pushes back from the backup ingest, starts the
+// recording of auto-recorded streams and starts the schedules whose window is already open.
+func (controller *OmeController) onStreamPublished(stream *model.Stream, sessionId primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(context.Background(), streamPublishedTimeout)
+	defer cancel()
+
+	err := controller.omeService.SnapshotStreamMediaInfo(ctx, stream, sessionId)
+	if err != nil {
+		fmt.Println("Error saving stream media info snapshot:", err)
+	}
+
+	if stream.ActiveIngest == "backup" {
+		err = controller.omeService.SwitchIngest(ctx, stream, "primary", "primary returned")
+		if err != nil {
+			fmt.Println("Error switching back to primary ingest:", err)
+		}
+	}
+
+	if stream.AutoRecord {
+		recording, err := controller.omeService.StartRecording(ctx, stream, &model.Recording{AutoStarted: true})
+		if err != nil {
+			fmt.Println("Error starting auto recording:", err)
+		} else {
+			fmt.Printf("Auto recording %s started for stream %s\n", recording.Id.Hex(), stream.Id.Hex())
+		}
+	}
+
+	err = controller.omeService.StartOpenSchedules(ctx, stream)
+	if err != nil {
+		fmt.Println("Error starting open schedules:", err)
+	}
+}
@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/api_response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PushTargetRequest describes a push destination. RtmpUrl is kept for older clients and
+// is treated as Url with the rtmp protocol.
+type PushTargetRequest struct {
+	Protocol     string                  `json:"protocol" binding:"omitempty,oneof=rtmp srt mpegts"`
+	Url          string                  `json:"url" binding:"required_without=RtmpUrl"`
+	RtmpUrl      string                  `json:"rtmp_url"`
+	StreamKey    string                  `json:"stream_key"`
+	VariantNames []string                `json:"variant_names"`
+	TrackIds     []int                   `json:"track_ids"`
+	RetryPolicy  *PushRetryPolicyRequest `json:"retry_policy"`
+}
+
+type PushRetryPolicyRequest struct {
+	MaxAttempts      int   `json:"max_attempts" binding:"required,min=1"`
+	InitialBackoffMs int64 `json:"initial_backoff_ms" binding:"gte=0"`
+	MaxBackoffMs     int64 `json:"max_backoff_ms" binding:"gte=0"`
+	GiveUpAfterMs    int64 `json:"give_up_after_ms" binding:"gte=0"`
+}
+
+type AddPushRequest struct {
+	PushTargetRequest
+}
+
+func (request PushTargetRequest) toPush() *model.Push {
+	push := &model.Push{
+		Protocol:     request.Protocol,
+		Url:          request.Url,
+		StreamKey:    request.StreamKey,
+		VariantNames: request.VariantNames,
+		TrackIds:     request.TrackIds,
+	}
+	if push.Url == "" {
+		push.Url = request.RtmpUrl
+	}
+	if push.Protocol == "" {
+		push.Protocol = "rtmp"
+	}
+	if push.Protocol == "rtmp" {
+		push.RtmpUrl = push.Url
+	}
+	if request.RetryPolicy != nil {
+		push.RetryPolicy = &model.PushRetryPolicy{
+			MaxAttempts:      request.RetryPolicy.MaxAttempts,
+			InitialBackoffMs: request.RetryPolicy.InitialBackoffMs,
+			MaxBackoffMs:     request.RetryPolicy.MaxBackoffMs,
+			GiveUpAfterMs:    request.RetryPolicy.GiveUpAfterMs,
+		}
+	}
+	return push
+}
+
+func (controller *OmeController) ListPushes(c *gin.Context) {
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	pushes, err := controller.omeService.ListPushesByStreamId(c, existingStream.Id)
+	if err != nil {
+		fmt.Println("Error listing pushes:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to list pushes",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Pushes fetched successfully", pushes)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) AddPush(c *gin.Context) {
+	var body AddPushRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	target := body.toPush()
+	err := controller.omeService.ValidatePushUrl(target.Protocol, target.Url)
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid push url",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	createdPush, err := controller.omeService.StartPush(c, existingStream, target)
+	if errors.Is(err, service.ErrDuplicatePushTarget) {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusConflict,
+			"Push target already exists",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if errors.Is(err, service.ErrNodeDown) {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusServiceUnavailable,
+			"OME node is down",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if err != nil {
+		fmt.Println("Error starting push:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to start push",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusCreated, "Push started successfully", createdPush)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) RemovePush(c *gin.Context) {
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	pushObjId, err := primitive.ObjectIDFromHex(c.Param("pushId"))
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid push ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	existingPush, err := controller.omeService.FindPushById(c, pushObjId)
+	if err != nil {
+		fmt.Println("Error finding push by ID:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to find push",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if existingPush == nil || existingPush.StreamId != existingStream.Id {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Push not found",
+			"Push does not exist for this stream", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	if existingPush.Status == "active" || existingPush.Status == "pending" || existingPush.Status == "retrying" {
+		err = controller.omeService.StopPush(c, existingStream, existingPush)
+		if err != nil {
+			fmt.Println("Error stopping push:", err)
+			errResponse := api_response.BuildErrorResponse(
+				http.StatusInternalServerError,
+				"Failed to stop push",
+				err.Error(), "")
+			c.JSON(errResponse.Code, errResponse)
+			return
+		}
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Push removed successfully", api_response.EmptyObj{})
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+// findStreamFromParam resolves the :id route param to a stream, writing an error response when it can't.
+func (controller *OmeController) findStreamFromParam(c *gin.Context) (*model.Stream, bool) {
+	objId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid stream ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return nil, false
+	}
+
+	existingStream, err := controller.omeService.FindStreamById(c, objId)
+	if err != nil {
+		fmt.Println("Error finding stream by ID:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to find stream",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return nil, false
+	}
+	if existingStream == nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Stream not found",
+			"Stream does not exist", "")
+		c.JSON(errResponse.Code, errResponse)
+		return nil, false
+	}
+	return existingStream, true
+}
@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/api_response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StartRecordingRequest mirrors OME's startRecord options. Interval (ms) and Schedule both split the
+// recording into files, so only one of them may be set.
+type StartRecordingRequest struct {
+	FilePath         string   `json:"file_path"`
+	InfoPath         string   `json:"info_path"`
+	Interval         int64    `json:"interval" binding:"gte=0,excluded_with=Schedule"`
+	Schedule         string   `json:"schedule"`
+	SegmentationRule string   `json:"segmentation_rule" binding:"omitempty,oneof=discontinuity continuity"`
+	VariantNames     []string `json:"variant_names"`
+}
+
+func (controller *OmeController) ListRecordings(c *gin.Context) {
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	err := controller.omeService.SyncStreamRecordings(c, existingStream.Id)
+	if err != nil {
+		fmt.Println("Error syncing stream recordings:", err)
+	}
+
+	recordings, err := controller.omeService.ListRecordingsByStreamId(c, existingStream.Id)
+	if err != nil {
+		fmt.Println("Error listing recordings:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to list recordings",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Recordings fetched successfully", recordings)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) StartRecording(c *gin.Context) {
+	var body StartRecordingRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	recording, err := controller.omeService.StartRecording(c, existingStream, &model.Recording{
+		FilePath:         body.FilePath,
+		InfoPath:         body.InfoPath,
+		Interval:         body.Interval,
+		Schedule:         body.Schedule,
+		SegmentationRule: body.SegmentationRule,
+		VariantNames:     body.VariantNames,
+	})
+	if errors.Is(err, service.ErrStreamNotLive) || errors.Is(err, service.ErrRecordingAlreadyActive) {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusConflict,
+			"Failed to start recording",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if errors.Is(err, service.ErrNodeDown) {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusServiceUnavailable,
+			"OME node is down",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if err != nil {
+		fmt.Println("Error starting recording:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to start recording",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusCreated, "Recording started successfully", recording)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) StopRecording(c *gin.Context) {
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	recordingObjId, err := primitive.ObjectIDFromHex(c.Param("recordingId"))
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid recording ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	existingRecording, err := controller.omeService.FindRecordingById(c, recordingObjId)
+	if err != nil {
+		fmt.Println("Error finding recording by ID:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to find recording",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if existingRecording == nil || existingRecording.StreamId != existingStream.Id {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Recording not found",
+			"Recording does not exist for this stream", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if existingRecording.State != "pending" && existingRecording.State != "ready" && existingRecording.State != "recording" {
+		apiResponseBody := api_response.BuildResponse(http.StatusOK, "Recording already stopped", existingRecording)
+		c.JSON(apiResponseBody.Code, apiResponseBody)
+		return
+	}
+
+	stoppedRecording, err := controller.omeService.StopRecording(c, existingRecording)
+	if err != nil {
+		fmt.Println("Error stopping recording:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to stop recording",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Recording stopped successfully", stoppedRecording)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) ListVodAssets(c *gin.Context) {
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	assets, err := controller.omeService.ListVodAssetsByStreamId(c, existingStream.Id)
+	if err != nil {
+		fmt.Println("Error listing vod assets:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to list vod assets",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Vod assets fetched successfully", assets)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/api_response"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ScheduleRequest struct {
+	Name         string              `json:"name"`
+	StartAt      time.Time           `json:"start_at" binding:"required"`
+	EndAt        time.Time           `json:"end_at" binding:"required,gtfield=StartAt"`
+	Destinations []PushTargetRequest `json:"destinations" binding:"required,min=1,dive"`
+}
+
+// toDestinations converts the requested push targets into schedule destinations, validating
+// their urls the same way AddPush does.
+func (controller *OmeController) toDestinations(request ScheduleRequest) ([]model.ScheduleDestination, error) {
+	destinations := make([]model.ScheduleDestination, 0, len(request.Destinations))
+	for _, target := range request.Destinations {
+		push := target.toPush()
+		err := controller.omeService.ValidatePushUrl(push.Protocol, push.Url)
+		if err != nil {
+			return nil, err
+		}
+		destinations = append(destinations, model.ScheduleDestination{
+			Protocol:     push.Protocol,
+			Url:          push.Url,
+			StreamKey:    push.StreamKey,
+			VariantNames: push.VariantNames,
+			TrackIds:     push.TrackIds,
+			RetryPolicy:  push.RetryPolicy,
+		})
+	}
+	return destinations, nil
+}
+
+func (controller *OmeController) ListSchedules(c *gin.Context) {
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	schedules, err := controller.omeService.ListSchedulesByStreamId(c, existingStream.Id)
+	if err != nil {
+		fmt.Println("Error listing schedules:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to list schedules",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Schedules fetched successfully", schedules)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) CreateSchedule(c *gin.Context) {
+	var body ScheduleRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	destinations, err := controller.toDestinations(body)
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid push url",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	schedule, err := controller.omeService.CreateSchedule(c, &model.Schedule{
+		StreamId:     existingStream.Id,
+		Name:         body.Name,
+		StartAt:      body.StartAt,
+		EndAt:        body.EndAt,
+		Destinations: destinations,
+	})
+	if err != nil {
+		fmt.Println("Error creating schedule:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to create schedule",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusCreated, "Schedule created successfully", schedule)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) GetSchedule(c *gin.Context) {
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+	existingSchedule, ok := controller.findScheduleFromParam(c, existingStream)
+	if !ok {
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Schedule fetched successfully", existingSchedule)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+// UpdateSchedule replaces the window and destinations of a schedule that hasn't started yet.
+func (controller *OmeController) UpdateSchedule(c *gin.Context) {
+	var body ScheduleRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+	existingSchedule, ok := controller.findScheduleFromParam(c, existingStream)
+	if !ok {
+		return
+	}
+	if existingSchedule.Status != "scheduled" {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusConflict,
+			"Schedule can't be updated",
+			"Schedule is "+existingSchedule.Status, "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	destinations, err := controller.toDestinations(body)
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid push url",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	updatedSchedule, err := controller.omeService.UpdateScheduleByID(c, existingSchedule.Id, bson.M{
+		"name":         body.Name,
+		"start_at":     body.StartAt,
+		"end_at":       body.EndAt,
+		"destinations": destinations,
+	})
+	if err != nil {
+		fmt.Println("Error updating schedule:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to update schedule",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Schedule updated successfully", updatedSchedule)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+// DeleteSchedule removes a schedule, stopping its pushes when it is running.
+func (controller *OmeController) DeleteSchedule(c *gin.Context) {
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+	existingSchedule, ok := controller.findScheduleFromParam(c, existingStream)
+	if !ok {
+		return
+	}
+
+	err := controller.omeService.DeleteSchedule(c, existingSchedule)
+	if err != nil {
+		fmt.Println("Error deleting schedule:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to delete schedule",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Schedule deleted successfully", api_response.EmptyObj{})
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+// findScheduleFromParam resolves the :scheduleId route param to a schedule of the stream, writing
+// an error response when it can't.
+func (controller *OmeController) findScheduleFromParam(c *gin.Context, stream *model.Stream) (*model.Schedule, bool) {
+	scheduleObjId, err := primitive.ObjectIDFromHex(c.Param("scheduleId"))
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid schedule ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return nil, false
+	}
+
+	existingSchedule, err := controller.omeService.FindScheduleById(c, scheduleObjId)
+	if err != nil {
+		fmt.Println("Error finding schedule by ID:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to find schedule",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return nil, false
+	}
+	if existingSchedule == nil || existingSchedule.StreamId != stream.Id {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Schedule not found",
+			"Schedule does not exist for this stream", "")
+		c.JSON(errResponse.Code, errResponse)
+		return nil, false
+	}
+	return existingSchedule, true
+}
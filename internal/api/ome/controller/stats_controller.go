@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/api_response"
+)
+
+func (controller *OmeController) GetStreamStats(c *gin.Context) {
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	stats, err := controller.omeService.GetStreamStats(existingStream)
+	if err != nil {
+		fmt.Println("Error fetching stream stats:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadGateway,
+			"Failed to fetch stream stats",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream stats fetched successfully", stats)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+const (
+	defaultMetricsWindow = time.Hour
+	defaultMetricsStep   = time.Minute
+	maxMetricsPoints     = 1000
+)
+
+type StreamMetricsQuery struct {
+	From *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Step string     `form:"step"`
+}
+
+func (controller *OmeController) GetStreamMetrics(c *gin.Context) {
+	var query StreamMetricsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid query parameters",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	to := time.Now()
+	if query.To != nil {
+		to = *query.To
+	}
+	from := to.Add(-defaultMetricsWindow)
+	if query.From != nil {
+		from = *query.From
+	}
+	step := defaultMetricsStep
+	if query.Step != "" {
+		parsedStep, err := time.ParseDuration(query.Step)
+		if err != nil || parsedStep < time.Second {
+			errResponse := api_response.BuildErrorResponse(
+				http.StatusBadRequest,
+				"Invalid query parameters",
+				"step must be a duration of at least 1s, e.g. 30s or 5m", "")
+			c.JSON(errResponse.Code, errResponse)
+			return
+		}
+		step = parsedStep
+	}
+	if !from.Before(to) {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid query parameters",
+			"from must be before to", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if to.Sub(from)/step > maxMetricsPoints {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid query parameters",
+			fmt.Sprintf("range would return more than %d points, increase step", maxMetricsPoints), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	points, err := controller.omeService.GetStreamMetrics(c, existingStream.Id, from, to, step)
+	if err != nil {
+		fmt.Println("Error fetching stream metrics:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to fetch stream metrics",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream metrics fetched successfully", gin.H{
+		"stream_id": existingStream.Id.Hex(),
+		"from":      from,
+		"to":        to,
+		"step":      step.String(),
+		"points":    points,
+	})
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) GetStreamInfo(c *gin.Context) {
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	mediaInfo, err := controller.omeService.GetStreamMediaInfo(existingStream)
+	if err != nil {
+		fmt.Println("Error fetching stream info:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadGateway,
+			"Failed to fetch stream info",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if mediaInfo == nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Stream is not live",
+			"Stream is not being published on OME", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream info fetched successfully", mediaInfo)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/api_response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultStreamPageLimit = 20
+	maxStreamPageLimit     = 100
+)
+
+type ListStreamsQuery struct {
+	Status          string     `form:"status"`
+	Protocol        string     `form:"protocol"`
+	ServerIpAddress string     `form:"server_ip_address"`
+	ExternalId      string     `form:"external_id"`
+	CreatedFrom     *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo       *time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	SortBy          string     `form:"sort_by" binding:"omitempty,oneof=created_at updated_at status external_id"`
+	SortOrder       string     `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+	Page            int64      `form:"page" binding:"omitempty,min=1"`
+	Limit           int64      `form:"limit" binding:"omitempty,min=1"`
+}
+
+func (controller *OmeController) ListStreams(c *gin.Context) {
+	var query ListStreamsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid query parameters",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	streamFilter := service.StreamFilter{
+		Status:          query.Status,
+		Protocol:        query.Protocol,
+		ServerIpAddress: query.ServerIpAddress,
+		ExternalId:      query.ExternalId,
+		CreatedFrom:     query.CreatedFrom,
+		CreatedTo:       query.CreatedTo,
+		SortBy:          query.SortBy,
+		SortOrder:       -1,
+		Page:            query.Page,
+		Limit:           query.Limit,
+	}
+	if streamFilter.SortBy == "" {
+		streamFilter.SortBy = "created_at"
+	}
+	if query.SortOrder == "asc" {
+		streamFilter.SortOrder = 1
+	}
+	if streamFilter.Page == 0 {
+		streamFilter.Page = 1
+	}
+	if streamFilter.Limit == 0 {
+		streamFilter.Limit = defaultStreamPageLimit
+	}
+	if streamFilter.Limit > maxStreamPageLimit {
+		streamFilter.Limit = maxStreamPageLimit
+	}
+
+	streams, total, err := controller.omeService.ListStreams(c, streamFilter)
+	if err != nil {
+		fmt.Println("Error listing streams:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to list streams",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Streams fetched successfully", map[string]interface{}{
+		"streams": streams,
+		"total":   total,
+		"page":    streamFilter.Page,
+		"limit":   streamFilter.Limit,
+	})
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) GetStream(c *gin.Context) {
+	objId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid stream ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	existingStream, err := controller.omeService.FindStreamById(c, objId)
+	if err != nil {
+		fmt.Println("Error finding stream by ID:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to find stream",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if existingStream == nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Stream not found",
+			"Stream does not exist", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream fetched successfully", existingStream)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) GetStreamByExternalId(c *gin.Context) {
+	existingStream, err := controller.omeService.GetStreamByExternalId(c, c.Param("externalId"))
+	if err != nil {
+		fmt.Println("Error finding stream by external ID:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to find stream",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if existingStream == nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Stream not found",
+			"Stream does not exist", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream fetched successfully", existingStream)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
+
+func (controller *OmeController) GetStreamUrls(c *gin.Context) {
+	existingStream, ok := controller.findStreamFromParam(c)
+	if !ok {
+		return
+	}
+
+	urls, err := controller.omeService.GetStreamUrls(c, existingStream)
+	if err != nil {
+		fmt.Println("Error building stream urls:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to build stream urls",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream urls fetched successfully", urls)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
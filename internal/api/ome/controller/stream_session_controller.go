@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/api_response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (controller *OmeController) ListStreamSessions(c *gin.Context) {
+	objId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusBadRequest,
+			"Invalid stream ID",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	existingStream, err := controller.omeService.FindStreamById(c, objId)
+	if err != nil {
+		fmt.Println("Error finding stream by ID:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to find stream",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+	if existingStream == nil {
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusNotFound,
+			"Stream not found",
+			"Stream does not exist", "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	sessions, err := controller.omeService.ListStreamSessions(c, objId)
+	if err != nil {
+		fmt.Println("Error listing stream sessions:", err)
+		errResponse := api_response.BuildErrorResponse(
+			http.StatusInternalServerError,
+			"Failed to list stream sessions",
+			err.Error(), "")
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	apiResponseBody := api_response.BuildResponse(http.StatusOK, "Stream sessions fetched successfully", sessions)
+	c.JSON(apiResponseBody.Code, apiResponseBody)
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Channel is a 24/7 OME scheduled channel. Its ID is used as the OME stream name.
+type Channel struct {
+	Id              primitive.ObjectID `json:"id" bson:"_id"`
+	Title           string             `json:"title" bson:"title"`
+	App             string             `json:"app" bson:"app"`
+	ServerIpAddress string             `json:"server_ip_address" bson:"server_ip_address"`
+	NodeId          primitive.ObjectID `json:"node_id,omitempty" bson:"node_id,omitempty"`
+	Programs        []ChannelProgram   `json:"programs" bson:"programs"`
+	Fallback        []ChannelItem      `json:"fallback,omitempty" bson:"fallback,omitempty"`
+	Status          string             `json:"status" bson:"status"` // "pending", "active", "failed"
+	FailureReason   string             `json:"failure_reason,omitempty" bson:"failure_reason,omitempty"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
+	DeletedAt       *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+type ChannelProgram struct {
+	Name        string        `json:"name" bson:"name"`
+	ScheduledAt time.Time     `json:"scheduled_at" bson:"scheduled_at"`
+	Repeat      bool          `json:"repeat" bson:"repeat"`
+	Items       []ChannelItem `json:"items" bson:"items"`
+}
+
+// ChannelItem plays a VOD asset or a live stream. DurationMs of -1 plays it to the end.
+type ChannelItem struct {
+	VodAssetId primitive.ObjectID `json:"vod_asset_id,omitempty" bson:"vod_asset_id,omitempty"`
+	StreamId   primitive.ObjectID `json:"stream_id,omitempty" bson:"stream_id,omitempty"`
+	Url        string             `json:"url" bson:"url"`
+	StartMs    int64              `json:"start_ms" bson:"start_ms"`
+	DurationMs int64              `json:"duration_ms" bson:"duration_ms"`
+}
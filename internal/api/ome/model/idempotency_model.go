@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// IdempotencyRecord stores the first response sent for an Idempotency-Key so retries can be replayed.
+// Id is the key scoped to the request method and path.
+type IdempotencyRecord struct {
+	Id           string    `json:"id" bson:"_id"`
+	RequestHash  string    `json:"request_hash" bson:"request_hash"`
+	Status       string    `json:"status" bson:"status"` // "processing", "completed"
+	ResponseCode int       `json:"response_code" bson:"response_code"`
+	ContentType  string    `json:"content_type" bson:"content_type"`
+	ResponseBody []byte    `json:"response_body" bson:"response_body"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at" bson:"expires_at"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OmeNode is an OME origin server streams can be placed on.
+type OmeNode struct {
+	Id                  primitive.ObjectID `json:"id" bson:"_id"`
+	Name                string             `json:"name" bson:"name"`
+	Region              string             `json:"region" bson:"region"`
+	IpAddress           string             `json:"ip_address" bson:"ip_address"` // Address the origin reports in webhooks
+	IngestUrl           string             `json:"ingest_url" bson:"ingest_url"` // Public base URL publishers connect to, e.g. https://ome1.example.com:3334
+	ApiUrl              string             `json:"api_url" bson:"api_url"`       // OME REST API base URL, e.g. http://10.0.0.5:8081
+	ApiToken            string             `json:"-" bson:"api_token"`
+	Capacity            int                `json:"capacity" bson:"capacity"` // Maximum live streams, 0 means unlimited
+	Endpoints           NodeEndpoints      `json:"endpoints" bson:"endpoints"`
+	HealthState         string             `json:"health_state" bson:"health_state"` // "unknown", "healthy", "degraded", "down"
+	LatencyMs           int64              `json:"latency_ms" bson:"latency_ms"`
+	ConsecutiveFailures int                `json:"consecutive_failures" bson:"consecutive_failures"`
+	LastError           string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	LastCheckedAt       *time.Time         `json:"last_checked_at,omitempty" bson:"last_checked_at,omitempty"`
+	CreatedAt           time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// NodeEndpoints describes how players and encoders reach a node. Unset fields fall back to the
+// host, scheme and port of IngestUrl, and to OME's default RTMP and SRT ports.
+type NodeEndpoints struct {
+	Hostname        string `json:"hostname,omitempty" bson:"hostname,omitempty"`
+	BasePath        string `json:"base_path,omitempty" bson:"base_path,omitempty"`     // path prefix of WebRTC, WHIP and HTTP playback behind a proxy
+	Tls             *bool  `json:"tls,omitempty" bson:"tls,omitempty"`                 // follows the ingest URL's scheme when unset
+	WebRtcPort      int    `json:"webrtc_port,omitempty" bson:"webrtc_port,omitempty"` // WebRTC signalling and WHIP
+	HttpPort        int    `json:"http_port,omitempty" bson:"http_port,omitempty"`     // LL-HLS, HLS and DASH
+	RtmpPort        int    `json:"rtmp_port,omitempty" bson:"rtmp_port,omitempty"`
+	SrtIngestPort   int    `json:"srt_ingest_port,omitempty" bson:"srt_ingest_port,omitempty"`
+	SrtPlaybackPort int    `json:"srt_playback_port,omitempty" bson:"srt_playback_port,omitempty"`
+}
@@ -9,9 +9,52 @@ import (
 type Push struct {
 	Id              primitive.ObjectID `json:"id" bson:"_id"`
 	StreamId        primitive.ObjectID `json:"stream_id" bson:"stream_id"`
-	RtmpUrl         string             `json:"rtmp_url" bson:"rtmp_url"`
+	App             string             `json:"app" bson:"app,omitempty"`
+	Protocol        string             `json:"protocol" bson:"protocol"` // "rtmp", "srt", "mpegts"
+	Url             string             `json:"url" bson:"url"`
+	RtmpUrl         string             `json:"rtmp_url,omitempty" bson:"rtmp_url,omitempty"` // Deprecated: set for rtmp pushes only, use Url
+	StreamKey       string             `json:"-" bson:"stream_key,omitempty"`
+	VariantNames    []string           `json:"variant_names,omitempty" bson:"variant_names,omitempty"`
+	TrackIds        []int              `json:"track_ids,omitempty" bson:"track_ids,omitempty"`
 	Status          string             `json:"status" bson:"status"`
+	OmeState        string             `json:"ome_state,omitempty" bson:"ome_state,omitempty"`
+	SentBytes       int64              `json:"sent_bytes" bson:"sent_bytes"`
+	TotalSentTime   int64              `json:"total_sent_time" bson:"total_sent_time"`
+	ReconciledAt    *time.Time         `json:"reconciled_at,omitempty" bson:"reconciled_at,omitempty"`
+	RetryPolicy     *PushRetryPolicy   `json:"retry_policy,omitempty" bson:"retry_policy,omitempty"`
+	RetryCount      int                `json:"retry_count" bson:"retry_count"`
+	FailedSince     *time.Time         `json:"failed_since,omitempty" bson:"failed_since,omitempty"`
+	RecoveredAt     *time.Time         `json:"recovered_at,omitempty" bson:"recovered_at,omitempty"`
+	NextRetryAt     *time.Time         `json:"next_retry_at,omitempty" bson:"next_retry_at,omitempty"`
+	FailureReason   string             `json:"failure_reason,omitempty" bson:"failure_reason,omitempty"`
+	RetryAttempts   []PushRetryAttempt `json:"retry_attempts,omitempty" bson:"retry_attempts,omitempty"`
 	ServerIpAddress string             `json:"server_ip_address" bson:"server_ip_address"`
 	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
+	DeletedAt       *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+// PushRetryPolicy enables automatic reconnects of a failed push. The delay between attempts
+// starts at InitialBackoffMs and doubles up to MaxBackoffMs. Retrying stops after MaxAttempts
+// or once the push has been failing for GiveUpAfterMs, whichever comes first.
+type PushRetryPolicy struct {
+	MaxAttempts      int   `json:"max_attempts" bson:"max_attempts"`
+	InitialBackoffMs int64 `json:"initial_backoff_ms" bson:"initial_backoff_ms"`
+	MaxBackoffMs     int64 `json:"max_backoff_ms" bson:"max_backoff_ms"`
+	GiveUpAfterMs    int64 `json:"give_up_after_ms" bson:"give_up_after_ms"`
+}
+
+type PushRetryAttempt struct {
+	Attempt     int       `json:"attempt" bson:"attempt"`
+	AttemptedAt time.Time `json:"attempted_at" bson:"attempted_at"`
+	Error       string    `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// DestinationUrl returns the push destination, falling back to RtmpUrl for records
+// created before pushes supported other protocols.
+func (p Push) DestinationUrl() string {
+	if p.Url != "" {
+		return p.Url
+	}
+	return p.RtmpUrl
 }
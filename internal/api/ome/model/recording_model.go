@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Recording is a server-side recording of a stream made by OME. Its ID is used as the OME record ID.
+type Recording struct {
+	Id               primitive.ObjectID `json:"id" bson:"_id"`
+	StreamId         primitive.ObjectID `json:"stream_id" bson:"stream_id"`
+	ServerIpAddress  string             `json:"server_ip_address" bson:"server_ip_address"`
+	App              string             `json:"app" bson:"app"`
+	FilePath         string             `json:"file_path,omitempty" bson:"file_path,omitempty"`
+	InfoPath         string             `json:"info_path,omitempty" bson:"info_path,omitempty"`
+	OutputFilePath   string             `json:"output_file_path,omitempty" bson:"output_file_path,omitempty"`
+	OutputInfoPath   string             `json:"output_info_path,omitempty" bson:"output_info_path,omitempty"`
+	Interval         int64              `json:"interval,omitempty" bson:"interval,omitempty"` // split interval in ms
+	Schedule         string             `json:"schedule,omitempty" bson:"schedule,omitempty"`
+	SegmentationRule string             `json:"segmentation_rule,omitempty" bson:"segmentation_rule,omitempty"` // "discontinuity", "continuity"
+	VariantNames     []string           `json:"variant_names,omitempty" bson:"variant_names,omitempty"`
+	State            string             `json:"state" bson:"state"` // "pending", "ready", "recording", "stopping", "stopped", "error", "failed"
+	FailureReason    string             `json:"failure_reason,omitempty" bson:"failure_reason,omitempty"`
+	Sequence         int                `json:"sequence" bson:"sequence"`
+	TotalRecordBytes int64              `json:"total_record_bytes" bson:"total_record_bytes"`
+	TotalRecordTime  int64              `json:"total_record_time" bson:"total_record_time"`
+	AutoStarted      bool               `json:"auto_started" bson:"auto_started"`
+	StartedAt        *time.Time         `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	FinishedAt       *time.Time         `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+	SyncedAt         *time.Time         `json:"synced_at,omitempty" bson:"synced_at,omitempty"`
+	CatalogedAt      *time.Time         `json:"cataloged_at,omitempty" bson:"cataloged_at,omitempty"`
+	CatalogFailedAt  *time.Time         `json:"catalog_failed_at,omitempty" bson:"catalog_failed_at,omitempty"`
+	CatalogFailure   string             `json:"catalog_failure,omitempty" bson:"catalog_failure,omitempty"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+}
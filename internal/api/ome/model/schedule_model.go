@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Schedule pushes a stream to a set of destinations between StartAt and EndAt.
+type Schedule struct {
+	Id            primitive.ObjectID    `json:"id" bson:"_id"`
+	StreamId      primitive.ObjectID    `json:"stream_id" bson:"stream_id"`
+	Name          string                `json:"name,omitempty" bson:"name,omitempty"`
+	StartAt       time.Time             `json:"start_at" bson:"start_at"`
+	EndAt         time.Time             `json:"end_at" bson:"end_at"`
+	Destinations  []ScheduleDestination `json:"destinations" bson:"destinations"`
+	Status        string                `json:"status" bson:"status"` // "scheduled", "starting", "running", "completed", "missed", "cancelled"
+	PushIds       []primitive.ObjectID  `json:"push_ids" bson:"push_ids"`
+	LastError     string                `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	LastAttemptAt *time.Time            `json:"last_attempt_at,omitempty" bson:"last_attempt_at,omitempty"`
+	StartedAt     *time.Time            `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	EndedAt       *time.Time            `json:"ended_at,omitempty" bson:"ended_at,omitempty"`
+	CreatedAt     time.Time             `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at" bson:"updated_at"`
+	DeletedAt     *time.Time            `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+// ScheduleDestination is a push target started when its schedule opens.
+type ScheduleDestination struct {
+	Protocol     string           `json:"protocol" bson:"protocol"`
+	Url          string           `json:"url" bson:"url"`
+	StreamKey    string           `json:"-" bson:"stream_key,omitempty"`
+	VariantNames []string         `json:"variant_names,omitempty" bson:"variant_names,omitempty"`
+	TrackIds     []int            `json:"track_ids,omitempty" bson:"track_ids,omitempty"`
+	RetryPolicy  *PushRetryPolicy `json:"retry_policy,omitempty" bson:"retry_policy,omitempty"`
+}
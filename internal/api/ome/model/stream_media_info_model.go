@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// StreamMediaInfo describes what a publisher is sending and the renditions OME produces from it.
+// Bitrates are in bits per second.
+type StreamMediaInfo struct {
+	SourceType string           `json:"source_type" bson:"source_type"`
+	Tracks     []MediaTrack     `json:"tracks" bson:"tracks"`
+	Renditions []MediaRendition `json:"renditions" bson:"renditions"`
+	CapturedAt time.Time        `json:"captured_at" bson:"captured_at"`
+}
+
+type MediaTrack struct {
+	Id               int     `json:"id" bson:"id"`
+	Name             string  `json:"name,omitempty" bson:"name,omitempty"`
+	Type             string  `json:"type" bson:"type"`
+	Codec            string  `json:"codec" bson:"codec"`
+	Bitrate          int64   `json:"bitrate" bson:"bitrate"`
+	Bypass           bool    `json:"bypass" bson:"bypass"`
+	Width            int     `json:"width,omitempty" bson:"width,omitempty"`
+	Height           int     `json:"height,omitempty" bson:"height,omitempty"`
+	Framerate        float64 `json:"framerate,omitempty" bson:"framerate,omitempty"`
+	KeyFrameInterval float64 `json:"key_frame_interval,omitempty" bson:"key_frame_interval,omitempty"`
+	HasBframes       bool    `json:"has_bframes,omitempty" bson:"has_bframes,omitempty"`
+	SampleRate       int     `json:"sample_rate,omitempty" bson:"sample_rate,omitempty"`
+	Channels         int     `json:"channels,omitempty" bson:"channels,omitempty"`
+}
+
+type MediaRendition struct {
+	Name   string       `json:"name" bson:"name"`
+	Tracks []MediaTrack `json:"tracks" bson:"tracks"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StreamMetric is a single sample of a live stream's stats, stored in a time-series collection.
+type StreamMetric struct {
+	Timestamp     time.Time          `json:"timestamp" bson:"timestamp"`
+	StreamId      primitive.ObjectID `json:"stream_id" bson:"stream_id"`
+	Viewers       int                `json:"viewers" bson:"viewers"`
+	IngestBitrate int64              `json:"ingest_bitrate" bson:"ingest_bitrate"`
+	EgressBitrate int64              `json:"egress_bitrate" bson:"egress_bitrate"`
+	TotalBytesIn  int64              `json:"total_bytes_in" bson:"total_bytes_in"`
+	TotalBytesOut int64              `json:"total_bytes_out" bson:"total_bytes_out"`
+}
+
+// StreamMetricPoint is one bucket of a downsampled metric series.
+type StreamMetricPoint struct {
+	Timestamp        time.Time `json:"timestamp" bson:"_id"`
+	Samples          int       `json:"samples" bson:"samples"`
+	AvgViewers       float64   `json:"avg_viewers" bson:"avg_viewers"`
+	MaxViewers       int       `json:"max_viewers" bson:"max_viewers"`
+	AvgIngestBitrate float64   `json:"avg_ingest_bitrate" bson:"avg_ingest_bitrate"`
+	AvgEgressBitrate float64   `json:"avg_egress_bitrate" bson:"avg_egress_bitrate"`
+}
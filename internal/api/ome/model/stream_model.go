@@ -7,11 +7,27 @@ import (
 )
 
 type Stream struct {
-	Id              primitive.ObjectID `json:"id" bson:"_id"`
-	ServerIpAddress string             `json:"server_ip_address" bson:"server_ip_address"`
-	Protocol        string             `json:"protocol" bson:"protocol"`
-	Status          string             `json:"status" bson:"status"`
-	ExternalId      string             `json:"external_id" bson:"external_id"`
-	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
+	Id               primitive.ObjectID `json:"id" bson:"_id"`
+	ServerIpAddress  string             `json:"server_ip_address" bson:"server_ip_address"`
+	NodeId           primitive.ObjectID `json:"node_id,omitempty" bson:"node_id,omitempty"`
+	Protocol         string             `json:"protocol" bson:"protocol"`
+	Status           string             `json:"status" bson:"status"`
+	ExternalId       string             `json:"external_id" bson:"external_id"`
+	App              string             `json:"app" bson:"app,omitempty"`
+	MaxSessionMs     int64              `json:"max_session_ms" bson:"max_session_ms,omitempty"`
+	ViewerPolicy     string             `json:"viewer_policy" bson:"viewer_policy"` // "public", "token", "disabled"
+	ViewerToken      string             `json:"-" bson:"viewer_token,omitempty"`
+	AutoRecord       bool               `json:"auto_record" bson:"auto_record"`
+	BackupIngestName string             `json:"backup_ingest_name,omitempty" bson:"backup_ingest_name,omitempty"`
+	BackupStatus     string             `json:"backup_status,omitempty" bson:"backup_status,omitempty"` // "opening", "closing"
+	ActiveIngest     string             `json:"active_ingest,omitempty" bson:"active_ingest,omitempty"` // "primary", "backup"
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+	DeletedAt        *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+// IsLive reports whether a publisher is feeding the stream, either the primary or, after a
+// failover, the backup.
+func (s Stream) IsLive() bool {
+	return s.Status == "opening" || (s.ActiveIngest == "backup" && s.BackupStatus == "opening")
 }
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StreamSession struct {
+	Id              primitive.ObjectID `json:"id" bson:"_id"`
+	StreamId        primitive.ObjectID `json:"stream_id" bson:"stream_id"`
+	Protocol        string             `json:"protocol" bson:"protocol"`
+	ServerIpAddress string             `json:"server_ip_address" bson:"server_ip_address"`
+	ClientAddress   string             `json:"client_address" bson:"client_address"`
+	ClientPort      int                `json:"client_port" bson:"client_port"`
+	RealIp          string             `json:"real_ip" bson:"real_ip"`
+	UserAgent       string             `json:"user_agent" bson:"user_agent"`
+	Status          string             `json:"status" bson:"status"`
+	StartedAt       time.Time          `json:"started_at" bson:"started_at"`
+	EndedAt         *time.Time         `json:"ended_at" bson:"ended_at"`
+	DurationMs      int64              `json:"duration_ms" bson:"duration_ms"`
+	MediaInfo       *StreamMediaInfo   `json:"media_info,omitempty" bson:"media_info,omitempty"`
+	FailoverEvents  []FailoverEvent    `json:"failover_events,omitempty" bson:"failover_events,omitempty"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// FailoverEvent records pushes being switched between the primary and backup ingest of a stream.
+type FailoverEvent struct {
+	From           string    `json:"from" bson:"from"` // "primary", "backup"
+	To             string    `json:"to" bson:"to"`
+	Reason         string    `json:"reason" bson:"reason"`
+	SwitchedPushes int       `json:"switched_pushes" bson:"switched_pushes"`
+	Error          string    `json:"error,omitempty" bson:"error,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at" bson:"occurred_at"`
+}
+
+// IsPublishedBy reports whether the session belongs to the publisher connected from address:port.
+func (s StreamSession) IsPublishedBy(address string, port int) bool {
+	return s.ClientAddress == address && s.ClientPort == port
+}
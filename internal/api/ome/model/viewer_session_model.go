@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ViewerSession struct {
+	Id            primitive.ObjectID `json:"id" bson:"_id"`
+	StreamId      primitive.ObjectID `json:"stream_id" bson:"stream_id"`
+	Protocol      string             `json:"protocol" bson:"protocol"`
+	ClientAddress string             `json:"client_address" bson:"client_address"`
+	ClientPort    int                `json:"client_port" bson:"client_port"`
+	RealIp        string             `json:"real_ip" bson:"real_ip"`
+	UserAgent     string             `json:"user_agent" bson:"user_agent"`
+	Status        string             `json:"status" bson:"status"`
+	StartedAt     time.Time          `json:"started_at" bson:"started_at"`
+	EndedAt       *time.Time         `json:"ended_at" bson:"ended_at"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VodAsset is a finished recording file moved into VOD storage. Assets are recorded as "pending"
+// before the file is moved and become "ready" once it is in storage.
+type VodAsset struct {
+	Id          primitive.ObjectID `json:"id" bson:"_id"`
+	StreamId    primitive.ObjectID `json:"stream_id" bson:"stream_id"`
+	RecordingId primitive.ObjectID `json:"recording_id" bson:"recording_id"`
+	Sequence    int                `json:"sequence" bson:"sequence"`
+	SourcePath  string             `json:"source_path" bson:"source_path"`
+	Status      string             `json:"status" bson:"status"` // "pending", "ready"
+	Storage     string             `json:"storage" bson:"storage"`
+	StorageKey  string             `json:"storage_key" bson:"storage_key"`
+	FilePath    string             `json:"file_path" bson:"file_path"`
+	Container   string             `json:"container" bson:"container"`
+	DurationMs  int64              `json:"duration_ms" bson:"duration_ms"`
+	SizeBytes   int64              `json:"size_bytes" bson:"size_bytes"`
+	Checksum    string             `json:"checksum" bson:"checksum"` // sha256, hex encoded
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
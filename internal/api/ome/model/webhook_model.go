@@ -0,0 +1,32 @@
+package model
+
+// OmeWebhookRequest is the payload OME sends to AdmissionWebhooks.
+type OmeWebhookRequest struct {
+	Client  OmeWebhookClient      `json:"client"`
+	Request OmeWebhookRequestInfo `json:"request"`
+}
+
+type OmeWebhookClient struct {
+	Address   string `json:"address"`
+	Port      int    `json:"port"`
+	RealIP    string `json:"real_ip"`
+	UserAgent string `json:"user_agent"`
+}
+
+type OmeWebhookRequestInfo struct {
+	Direction string `json:"direction"` // "incoming", "outgoing"
+	NewUrl    string `json:"new_url"`
+	Protocol  string `json:"protocol"`
+	Status    string `json:"status"` // "opening", "closing"
+	Time      string `json:"time"`
+	Url       string `json:"url"` // We extract name from here
+}
+
+// OmeWebhookResponse is the admission decision returned to OME.
+// Lifetime is in milliseconds, 0 means unlimited. Reason is only shown when the request is denied.
+type OmeWebhookResponse struct {
+	Allowed  bool   `json:"allowed"`
+	NewUrl   string `json:"new_url,omitempty"`
+	Lifetime int64  `json:"lifetime,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
@@ -5,11 +5,44 @@ import (
 	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/controller"
 )
 
-func Setup(group *gin.RouterGroup, omeController *controller.OmeController) {
+func Setup(group *gin.RouterGroup, omeController *controller.OmeController, idempotency gin.HandlerFunc) {
 	group.GET("", controller.Index())
 	group.POST("/ome/webhook", omeController.Webhook)
-	group.POST("/ome/create", omeController.CreateStream)
-	group.POST("/ome/startPush", omeController.StartPush)
-	group.POST("/ome/stopPush", omeController.StopPush)
+	group.POST("/ome/create", idempotency, omeController.CreateStream)
+	group.POST("/ome/startPush", idempotency, omeController.StartPush)
+	group.POST("/ome/stopPush", idempotency, omeController.StopPush)
+	group.GET("/ome/streams", omeController.ListStreams)
+	group.GET("/ome/streams/by-external-id/:externalId", omeController.GetStreamByExternalId)
+	group.GET("/ome/streams/:id", omeController.GetStream)
+	group.DELETE("/ome/streams/:id", idempotency, omeController.DeleteStream)
+	group.GET("/ome/streams/:id/sessions", omeController.ListStreamSessions)
+	group.GET("/ome/streams/:id/stats", omeController.GetStreamStats)
+	group.GET("/ome/streams/:id/metrics", omeController.GetStreamMetrics)
+	group.GET("/ome/streams/:id/info", omeController.GetStreamInfo)
+	group.GET("/ome/streams/:id/urls", omeController.GetStreamUrls)
+	group.GET("/ome/streams/:id/recordings", omeController.ListRecordings)
+	group.POST("/ome/streams/:id/recordings", idempotency, omeController.StartRecording)
+	group.POST("/ome/streams/:id/recordings/:recordingId/stop", idempotency, omeController.StopRecording)
+	group.GET("/ome/streams/:id/vod", omeController.ListVodAssets)
+	group.GET("/ome/streams/:id/schedules", omeController.ListSchedules)
+	group.POST("/ome/streams/:id/schedules", idempotency, omeController.CreateSchedule)
+	group.GET("/ome/streams/:id/schedules/:scheduleId", omeController.GetSchedule)
+	group.PUT("/ome/streams/:id/schedules/:scheduleId", idempotency, omeController.UpdateSchedule)
+	group.DELETE("/ome/streams/:id/schedules/:scheduleId", idempotency, omeController.DeleteSchedule)
+	group.POST("/ome/streams/:id/stop", idempotency, omeController.StopStream)
+	group.POST("/ome/streams/:id/enable", idempotency, omeController.EnableStream)
+	group.GET("/ome/streams/:id/pushes", omeController.ListPushes)
+	group.POST("/ome/streams/:id/pushes", idempotency, omeController.AddPush)
+	group.DELETE("/ome/streams/:id/pushes/:pushId", idempotency, omeController.RemovePush)
+	group.GET("/ome/nodes", omeController.ListNodes)
+	group.POST("/ome/nodes", idempotency, omeController.CreateNode)
+	group.GET("/ome/nodes/:id", omeController.GetNode)
+	group.PUT("/ome/nodes/:id", idempotency, omeController.UpdateNode)
+	group.DELETE("/ome/nodes/:id", idempotency, omeController.DeleteNode)
+	group.GET("/ome/channels", omeController.ListChannels)
+	group.POST("/ome/channels", idempotency, omeController.CreateChannel)
+	group.GET("/ome/channels/:id", omeController.GetChannel)
+	group.PUT("/ome/channels/:id", idempotency, omeController.UpdateChannel)
+	group.DELETE("/ome/channels/:id", idempotency, omeController.DeleteChannel)
 
 }
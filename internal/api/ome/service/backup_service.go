@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	ingestWaitAttempts = 5
+	ingestWaitDelay    = 2 * time.Second
+)
+
+func (s OmeService) FindStreamByBackupIngestName(ctx context.Context, name string) (*model.Stream, error) {
+	var result model.Stream
+
+	err := s.streamCollection.FindOne(ctx, bson.M{"backup_ingest_name": name, "deleted_at": nil}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetStreamIngestName returns the OME stream name pushes of the stream are currently taken from.
+func (s OmeService) GetStreamIngestName(stream *model.Stream) string {
+	if stream.ActiveIngest == "backup" && stream.BackupIngestName != "" {
+		return stream.BackupIngestName
+	}
+	return stream.Id.Hex()
+}
+
+// CheckBackupAdmission decides whether a backup encoder may publish for the stream. The backup
+// has to publish to the stream's origin, even when the primary isn't live, since pushes can only
+// switch between streams on one OME server.
+func (s OmeService) CheckBackupAdmission(stream *model.Stream, serverIp string, requestUrl string) model.OmeWebhookResponse {
+	switch stream.Status {
+	case "ended":
+		return model.OmeWebhookResponse{Allowed: false, Reason: "stream has ended"}
+	case "disabled":
+		return model.OmeWebhookResponse{Allowed: false, Reason: "stream is disabled"}
+	}
+	if stream.ServerIpAddress != "" && serverIp != "" && stream.ServerIpAddress != serverIp {
+		return model.OmeWebhookResponse{Allowed: false, Reason: "backup must publish to the primary's origin"}
+	}
+	if stream.BackupStatus == "opening" && !config.AppConfig.OME_ALLOW_TAKEOVER {
+		return model.OmeWebhookResponse{Allowed: false, Reason: "backup is already live"}
+	}
+	return s.BuildAdmissionResponse(stream, requestUrl)
+}
+
+// SwitchIngest moves the active pushes of a stream onto its primary or backup ingest by
+// re-issuing them on OME with the same push IDs. Pushes that fail to switch are marked failed so
+// their retry policy takes over. The switch is recorded on the stream's latest session.
+func (s OmeService) SwitchIngest(ctx context.Context, stream *model.Stream, to string, reason string) error {
+	from := stream.ActiveIngest
+	if from == "" {
+		from = "primary"
+	}
+	_, err := s.UpdateStreamByID(ctx, stream.Id, bson.M{"active_ingest": to})
+	if err != nil {
+		return fmt.Errorf("failed to update active ingest: %w", err)
+	}
+	stream.ActiveIngest = to
+
+	pushes, err := s.FindPushesByStreamIdAndStatus(ctx, stream.Id, "active")
+	if err != nil {
+		return err
+	}
+
+	app := s.GetStreamApp(stream)
+	sourceStreamName := s.GetPushSourceStreamName(s.GetStreamIngestName(stream))
+	switchedPushes := 0
+	var switchErrs []error
+	for i := range pushes {
+		push := &pushes[i]
+		// OME drops pushes whose source stream ended, but the source may still be live when switching back.
+		_ = s.omeHttpClient.StopPush(stream.ServerIpAddress, app, push.Id.Hex())
+
+		_, err := s.omeHttpClient.StartPush(stream.ServerIpAddress, app, sourceStreamName, push.Id.Hex(), s.GetPushOptions(push))
+		if err != nil {
+			switchErrs = append(switchErrs, fmt.Errorf("push %s: %w", push.Id.Hex(), err))
+			markErr := s.MarkPushFailed(ctx, push, "failover to "+to+" failed: "+err.Error(), time.Now())
+			if markErr != nil {
+				switchErrs = append(switchErrs, markErr)
+			}
+			continue
+		}
+		switchedPushes++
+	}
+
+	event := model.FailoverEvent{
+		From:           from,
+		To:             to,
+		Reason:         reason,
+		SwitchedPushes: switchedPushes,
+		OccurredAt:     time.Now(),
+	}
+	if len(switchErrs) > 0 {
+		event.Error = errors.Join(switchErrs...).Error()
+	}
+	err = s.RecordFailoverEvent(ctx, stream, event)
+	return errors.Join(append(switchErrs, err)...)
+}
+
+// HandlePrimaryClosed fails a stream over to its backup when the primary publisher disconnects
+// while the backup is live. Streams ended by an operator are not failed over.
+func (s OmeService) HandlePrimaryClosed(ctx context.Context, stream *model.Stream) error {
+	if stream.BackupIngestName == "" || stream.BackupStatus != "opening" || stream.ActiveIngest == "backup" {
+		return nil
+	}
+	if stream.Status == "ended" || stream.Status == "disabled" {
+		return nil
+	}
+	return s.SwitchIngest(ctx, stream, "backup", "primary closed")
+}
+
+// HandleBackupClosed records the backup publisher leaving and moves pushes back to the primary
+// if the stream had failed over to the backup.
+func (s OmeService) HandleBackupClosed(ctx context.Context, stream *model.Stream) error {
+	_, err := s.UpdateStreamByID(ctx, stream.Id, bson.M{"backup_status": "closing"})
+	if err != nil {
+		return err
+	}
+	stream.BackupStatus = "closing"
+	if stream.ActiveIngest != "backup" {
+		return nil
+	}
+	if stream.Status == "opening" {
+		return s.SwitchIngest(ctx, stream, "primary", "backup closed")
+	}
+
+	// Neither publisher is live, the reconciler fails the pushes and their retry policies apply.
+	_, err = s.UpdateStreamByID(ctx, stream.Id, bson.M{"active_ingest": "primary"})
+	if err != nil {
+		return err
+	}
+	return s.RecordFailoverEvent(ctx, stream, model.FailoverEvent{
+		From:       "backup",
+		To:         "primary",
+		Reason:     "backup closed with no live primary",
+		OccurredAt: time.Now(),
+	})
+}
+
+// WaitForIngest waits for OME to create the named stream after its admission webhook was allowed.
+func (s OmeService) WaitForIngest(ctx context.Context, stream *model.Stream, streamName string) error {
+	for attempt := 1; attempt <= ingestWaitAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(ingestWaitDelay):
+		}
+
+		_, err := s.omeHttpClient.GetStreamDetails(stream.ServerIpAddress, defaultVhost, s.GetStreamApp(stream), streamName)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, http_clients.ErrStreamNotFound) {
+			return err
+		}
+	}
+	return errors.New("stream did not appear on OME")
+}
+
+// RecordFailoverEvent appends a failover event to the latest session of the stream.
+func (s OmeService) RecordFailoverEvent(ctx context.Context, stream *model.Stream, event model.FailoverEvent) error {
+	fmt.Printf("[audit] stream %s switched ingest from %s to %s: %s\n", stream.Id.Hex(), event.From, event.To, event.Reason)
+
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"started_at": -1})
+	err := s.streamSessionCollection.FindOneAndUpdate(ctx, bson.M{"stream_id": stream.Id}, bson.M{
+		"$push": bson.M{"failover_events": event},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}, opts).Err()
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrChannelSourceNotFound   = errors.New("channel item references a missing vod asset or stream")
+	ErrChannelSourceUnplayable = errors.New("channel item can't be played by the channel's node")
+)
+
+// ScheduledChannelTimeFormat is the program start time format OME expects.
+const ScheduledChannelTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// CreateChannel resolves the channel's items to OME urls, places the channel on a node and
+// creates it on OME. OME can only play live streams published to the same node, so channels with
+// live stream items are pinned to that node instead. When OME rejects it the channel is kept as
+// "failed" with the reason.
+func (s OmeService) CreateChannel(ctx context.Context, channel *model.Channel, region string) (*model.Channel, error) {
+	channel.Id = primitive.NewObjectID()
+	if channel.App == "" {
+		channel.App = config.AppConfig.OME_APP_NAME
+	}
+	sourceStream, err := s.resolveChannelItems(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	if sourceStream != nil {
+		channel.NodeId = sourceStream.NodeId
+		channel.ServerIpAddress = sourceStream.ServerIpAddress
+	} else {
+		node, err := s.PlaceStream(ctx, PlacementRequest{ExternalId: channel.Id.Hex(), Region: region})
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			channel.NodeId = node.Id
+			channel.ServerIpAddress = node.IpAddress
+		} else {
+			channel.ServerIpAddress = defaultServerIp()
+		}
+	}
+
+	channel.Status = "pending"
+	currentTime := time.Now()
+	channel.CreatedAt = currentTime
+	channel.UpdatedAt = currentTime
+	_, err = s.channelCollection.InsertOne(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel record: %w", err)
+	}
+
+	err = s.omeHttpClient.CreateScheduledChannel(channel.ServerIpAddress, channel.App, toScheduledChannel(channel))
+	if err != nil {
+		_, rollbackErr := s.UpdateChannelByID(ctx, channel.Id, bson.M{
+			"status":         "failed",
+			"failure_reason": err.Error(),
+		})
+		if rollbackErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("failed to roll back channel record: %w", rollbackErr))
+		}
+		return nil, err
+	}
+
+	return s.UpdateChannelByID(ctx, channel.Id, bson.M{
+		"status": "active",
+	})
+}
+
+// UpdateChannel replaces the title, programs and fallback of a channel on OME and in Mongo.
+// Channels whose creation failed are created on OME again. Live stream items must be published to
+// the channel's node.
+func (s OmeService) UpdateChannel(ctx context.Context, channel *model.Channel) (*model.Channel, error) {
+	sourceStream, err := s.resolveChannelItems(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	if sourceStream != nil && sourceStream.ServerIpAddress != channel.ServerIpAddress {
+		return nil, fmt.Errorf("%w: stream %s is on %s, the channel is on %s",
+			ErrChannelSourceUnplayable, sourceStream.Id.Hex(), sourceStream.ServerIpAddress, channel.ServerIpAddress)
+	}
+
+	scheduledChannel := toScheduledChannel(channel)
+	if channel.Status == "failed" {
+		err = s.omeHttpClient.CreateScheduledChannel(channel.ServerIpAddress, channel.App, scheduledChannel)
+	} else {
+		err = s.omeHttpClient.UpdateScheduledChannel(channel.ServerIpAddress, channel.App, scheduledChannel)
+		if errors.Is(err, http_clients.ErrStreamNotFound) {
+			// OME forgets scheduled channels created through the API when it restarts.
+			err = s.omeHttpClient.CreateScheduledChannel(channel.ServerIpAddress, channel.App, scheduledChannel)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UpdateChannelByID(ctx, channel.Id, bson.M{
+		"title":          channel.Title,
+		"programs":       channel.Programs,
+		"fallback":       channel.Fallback,
+		"status":         "active",
+		"failure_reason": "",
+	})
+}
+
+// DeleteChannel removes a channel from OME and soft-deletes it.
+func (s OmeService) DeleteChannel(ctx context.Context, channel *model.Channel) error {
+	err := s.omeHttpClient.DeleteScheduledChannel(channel.ServerIpAddress, channel.App, channel.Id.Hex())
+	if err != nil {
+		return err
+	}
+
+	currentTime := time.Now()
+	_, err = s.channelCollection.UpdateOne(ctx, bson.M{"_id": channel.Id, "deleted_at": nil}, bson.M{
+		"$set": bson.M{"deleted_at": currentTime, "updated_at": currentTime},
+	})
+	return err
+}
+
+func (s OmeService) ListChannels(ctx context.Context) ([]model.Channel, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := s.channelCollection.Find(ctx, bson.M{"deleted_at": nil}, opts)
+	if err != nil {
+		return nil, err
+	}
+	channels := make([]model.Channel, 0)
+	if err := cursor.All(ctx, &channels); err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+func (s OmeService) FindChannelById(ctx context.Context, id primitive.ObjectID) (*model.Channel, error) {
+	var result model.Channel
+
+	err := s.channelCollection.FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s OmeService) UpdateChannelByID(ctx context.Context, id primitive.ObjectID, update bson.M) (*model.Channel, error) {
+	update["updated_at"] = time.Now()
+
+	result, err := s.channelCollection.UpdateOne(ctx, bson.M{"_id": id, "deleted_at": nil}, bson.M{"$set": update})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+	return s.FindChannelById(ctx, id)
+}
+
+// resolveChannelItems fills in the OME url of every item from the vod asset or stream it references.
+// It returns one of the referenced live streams, all of which must be on the same node, or nil
+// when the channel only plays vod assets.
+func (s OmeService) resolveChannelItems(ctx context.Context, channel *model.Channel) (*model.Stream, error) {
+	var sourceStream *model.Stream
+	for i := range channel.Programs {
+		err := s.resolveItems(ctx, channel.Programs[i].Items, &sourceStream)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := s.resolveItems(ctx, channel.Fallback, &sourceStream)
+	if err != nil {
+		return nil, err
+	}
+	return sourceStream, nil
+}
+
+func (s OmeService) resolveItems(ctx context.Context, items []model.ChannelItem, sourceStream **model.Stream) error {
+	for i := range items {
+		item := &items[i]
+		if !item.VodAssetId.IsZero() {
+			asset, err := s.FindVodAssetById(ctx, item.VodAssetId)
+			if err != nil {
+				return err
+			}
+			if asset == nil {
+				return fmt.Errorf("%w: vod asset %s", ErrChannelSourceNotFound, item.VodAssetId.Hex())
+			}
+			item.Url, err = vodAssetUrl(asset)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		stream, err := s.FindStreamById(ctx, item.StreamId)
+		if err != nil {
+			return err
+		}
+		if stream == nil {
+			return fmt.Errorf("%w: stream %s", ErrChannelSourceNotFound, item.StreamId.Hex())
+		}
+		if *sourceStream != nil && (*sourceStream).ServerIpAddress != stream.ServerIpAddress {
+			return fmt.Errorf("%w: streams %s and %s are on different nodes",
+				ErrChannelSourceUnplayable, (*sourceStream).Id.Hex(), stream.Id.Hex())
+		}
+		*sourceStream = stream
+		item.Url = "stream://" + defaultVhost + "/" + s.GetStreamApp(stream) + "/" + stream.Id.Hex()
+	}
+	return nil
+}
+
+// vodAssetUrl returns the OME file url of a vod asset. OME resolves file urls against the
+// channel's MediaRootDir, which must point at VOD_STORAGE_DIR, so assets outside of it can't be played.
+func vodAssetUrl(asset *model.VodAsset) (string, error) {
+	storageDir, err := filepath.Abs(config.AppConfig.VOD_STORAGE_DIR)
+	if err != nil {
+		return "", err
+	}
+	filePath, err := filepath.Abs(asset.FilePath)
+	if err != nil {
+		return "", err
+	}
+	relativePath, err := filepath.Rel(storageDir, filePath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: vod asset %s is outside VOD_STORAGE_DIR", ErrChannelSourceUnplayable, asset.Id.Hex())
+	}
+	return "file://" + filepath.ToSlash(relativePath), nil
+}
+
+// defaultServerIp returns the host of OME_SERVER_BASE_URL, used when no nodes are registered.
+func defaultServerIp() string {
+	parsedUrl, err := url.Parse(config.AppConfig.OME_SERVER_BASE_URL)
+	if err != nil {
+		return ""
+	}
+	return parsedUrl.Hostname()
+}
+
+func toScheduledChannel(channel *model.Channel) http_clients.ScheduledChannel {
+	scheduledChannel := http_clients.ScheduledChannel{
+		Stream: http_clients.ScheduledChannelStream{
+			Name:       channel.Id.Hex(),
+			VideoTrack: true,
+			AudioTrack: true,
+		},
+		Programs: make([]http_clients.ScheduledChannelProgram, 0, len(channel.Programs)),
+	}
+	for _, program := range channel.Programs {
+		scheduledChannel.Programs = append(scheduledChannel.Programs, http_clients.ScheduledChannelProgram{
+			Name:      program.Name,
+			Scheduled: program.ScheduledAt.Format(ScheduledChannelTimeFormat),
+			Repeat:    program.Repeat,
+			Items:     toScheduledChannelItems(program.Items),
+		})
+	}
+	if len(channel.Fallback) > 0 {
+		scheduledChannel.FallbackProgram = &http_clients.ScheduledChannelFallback{
+			Items: toScheduledChannelItems(channel.Fallback),
+		}
+	}
+	return scheduledChannel
+}
+
+func toScheduledChannelItems(items []model.ChannelItem) []http_clients.ScheduledChannelItem {
+	scheduledItems := make([]http_clients.ScheduledChannelItem, 0, len(items))
+	for _, item := range items {
+		scheduledItems = append(scheduledItems, http_clients.ScheduledChannelItem{
+			Url:      item.Url,
+			Start:    item.StartMs,
+			Duration: item.DurationMs,
+		})
+	}
+	return scheduledItems
+}
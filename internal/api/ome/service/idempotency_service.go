@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ReserveIdempotencyKey claims a key for the current request. When the key was already used it
+// returns the existing record and false instead.
+func (s OmeService) ReserveIdempotencyKey(ctx context.Context, key string, requestHash string) (*model.IdempotencyRecord, bool, error) {
+	currentTime := time.Now()
+	record := &model.IdempotencyRecord{
+		Id:          key,
+		RequestHash: requestHash,
+		Status:      "processing",
+		CreatedAt:   currentTime,
+		ExpiresAt:   currentTime.Add(config.AppConfig.IDEMPOTENCY_TTL),
+	}
+
+	_, err := s.idempotencyCollection.InsertOne(ctx, record)
+	if err == nil {
+		return record, true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, false, err
+	}
+
+	var existing model.IdempotencyRecord
+	err = s.idempotencyCollection.FindOne(ctx, bson.M{"_id": key}).Decode(&existing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			// The record expired between the insert and the lookup, let the caller retry.
+			return nil, false, mongo.ErrNoDocuments
+		}
+		return nil, false, err
+	}
+	return &existing, false, nil
+}
+
+func (s OmeService) CompleteIdempotencyKey(ctx context.Context, key string, responseCode int, contentType string, responseBody []byte) error {
+	_, err := s.idempotencyCollection.UpdateByID(ctx, key, bson.M{"$set": bson.M{
+		"status":        "completed",
+		"response_code": responseCode,
+		"content_type":  contentType,
+		"response_body": responseBody,
+	}})
+	return err
+}
+
+// ReleaseIdempotencyKey forgets a reserved key so the request can be retried, e.g. after a server error.
+func (s OmeService) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	_, err := s.idempotencyCollection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
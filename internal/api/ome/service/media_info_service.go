@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	mediaInfoSnapshotAttempts = 5
+	mediaInfoSnapshotDelay    = 2 * time.Second
+)
+
+// GetStreamMediaInfo returns the input tracks and output renditions of a stream from the OME server
+// it is published on. It returns nil when the stream isn't live.
+func (s OmeService) GetStreamMediaInfo(stream *model.Stream) (*model.StreamMediaInfo, error) {
+	if stream.ServerIpAddress == "" {
+		return nil, nil
+	}
+
+	details, err := s.omeHttpClient.GetStreamDetails(stream.ServerIpAddress, defaultVhost, s.GetStreamApp(stream), stream.Id.Hex())
+	if err != nil {
+		if errors.Is(err, http_clients.ErrStreamNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	mediaInfo := &model.StreamMediaInfo{
+		SourceType: details.Input.SourceType,
+		Tracks:     toMediaTracks(details.Input.Tracks),
+		Renditions: []model.MediaRendition{},
+		CapturedAt: time.Now(),
+	}
+	for _, output := range details.Outputs {
+		mediaInfo.Renditions = append(mediaInfo.Renditions, model.MediaRendition{
+			Name:   output.Name,
+			Tracks: toMediaTracks(output.Tracks),
+		})
+	}
+	return mediaInfo, nil
+}
+
+// SnapshotStreamMediaInfo saves the media info of a stream on its session. OME only creates the
+// stream once the admission webhook has allowed it, so the lookup is retried for a few seconds.
+func (s OmeService) SnapshotStreamMediaInfo(ctx context.Context, stream *model.Stream, sessionId primitive.ObjectID) error {
+	for attempt := 1; attempt <= mediaInfoSnapshotAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(mediaInfoSnapshotDelay):
+		}
+
+		mediaInfo, err := s.GetStreamMediaInfo(stream)
+		if err != nil {
+			return err
+		}
+		if mediaInfo == nil {
+			continue
+		}
+
+		_, err = s.streamSessionCollection.UpdateOne(ctx, bson.M{"_id": sessionId}, bson.M{
+			"$set": bson.M{
+				"media_info": mediaInfo,
+				"updated_at": time.Now(),
+			},
+		})
+		return err
+	}
+	return errors.New("stream did not appear on OME")
+}
+
+func toMediaTracks(tracks []http_clients.StreamTrack) []model.MediaTrack {
+	mediaTracks := []model.MediaTrack{}
+	for _, track := range tracks {
+		mediaTrack := model.MediaTrack{
+			Id:   track.Id,
+			Name: track.Name,
+			Type: track.Type,
+		}
+		if track.Video != nil {
+			mediaTrack.Codec = track.Video.Codec
+			mediaTrack.Bitrate = latestBitrate(track.Video.BitrateLatest, track.Video.Bitrate)
+			mediaTrack.Bypass = track.Video.Bypass
+			mediaTrack.Width = track.Video.Width
+			mediaTrack.Height = track.Video.Height
+			mediaTrack.Framerate = track.Video.Framerate
+			if track.Video.FramerateLatest > 0 {
+				mediaTrack.Framerate = track.Video.FramerateLatest
+			}
+			mediaTrack.KeyFrameInterval = track.Video.KeyFrameInterval
+			mediaTrack.HasBframes = track.Video.HasBframes
+		}
+		if track.Audio != nil {
+			mediaTrack.Codec = track.Audio.Codec
+			mediaTrack.Bitrate = latestBitrate(track.Audio.BitrateLatest, track.Audio.Bitrate)
+			mediaTrack.Bypass = track.Audio.Bypass
+			mediaTrack.SampleRate = track.Audio.Samplerate
+			mediaTrack.Channels = track.Audio.Channel
+		}
+		mediaTracks = append(mediaTracks, mediaTrack)
+	}
+	return mediaTracks
+}
+
+// latestBitrate prefers the measured bitrate newer OME versions report over the configured one.
+func latestBitrate(latest json.Number, configured json.Number) int64 {
+	if bitrate, err := latest.Int64(); err == nil && bitrate > 0 {
+		return bitrate
+	}
+	bitrate, _ := configured.Int64()
+	return bitrate
+}
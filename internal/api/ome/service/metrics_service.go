@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// namespaceExistsCode is the MongoDB error code returned when creating a collection that already exists.
+const namespaceExistsCode = 48
+
+// EnsureMetricsCollection creates the stream_metrics time-series collection, or updates its retention
+// if it already exists. Time-series collections need MongoDB 5.0 or newer.
+func (s OmeService) EnsureMetricsCollection(ctx context.Context) error {
+	db := s.metricCollection.Database()
+	name := s.metricCollection.Name()
+	retentionSeconds := int64(config.AppConfig.METRICS_RETENTION.Seconds())
+
+	opts := options.CreateCollection().
+		SetTimeSeriesOptions(options.TimeSeries().
+			SetTimeField("timestamp").
+			SetMetaField("stream_id").
+			SetGranularity("seconds")).
+		SetExpireAfterSeconds(retentionSeconds)
+	err := db.CreateCollection(ctx, name, opts)
+	if err == nil {
+		return nil
+	}
+
+	var commandErr mongo.CommandError
+	if !errors.As(err, &commandErr) || commandErr.Code != namespaceExistsCode {
+		return err
+	}
+	return db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: name},
+		{Key: "expireAfterSeconds", Value: retentionSeconds},
+	}).Err()
+}
+
+func (s OmeService) RecordStreamMetric(ctx context.Context, streamId primitive.ObjectID, stats *StreamStats) error {
+	metric := model.StreamMetric{
+		Timestamp:     stats.FetchedAt,
+		StreamId:      streamId,
+		Viewers:       stats.Viewers,
+		IngestBitrate: stats.IngestBitrate,
+		EgressBitrate: stats.EgressBitrate,
+		TotalBytesIn:  stats.TotalBytesIn,
+		TotalBytesOut: stats.TotalBytesOut,
+	}
+	_, err := s.metricCollection.InsertOne(ctx, metric)
+	return err
+}
+
+// GetStreamMetrics returns the metrics of a stream between from and to, averaged into buckets of step.
+func (s OmeService) GetStreamMetrics(ctx context.Context, streamId primitive.ObjectID, from time.Time, to time.Time, step time.Duration) ([]model.StreamMetricPoint, error) {
+	stepMs := step.Milliseconds()
+	timestampMs := bson.M{"$toLong": "$timestamp"}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"stream_id": streamId,
+			"timestamp": bson.M{"$gte": from, "$lt": to},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$toDate": bson.M{"$subtract": bson.A{
+				timestampMs,
+				bson.M{"$mod": bson.A{timestampMs, stepMs}},
+			}}},
+			"samples":            bson.M{"$sum": 1},
+			"avg_viewers":        bson.M{"$avg": "$viewers"},
+			"max_viewers":        bson.M{"$max": "$viewers"},
+			"avg_ingest_bitrate": bson.M{"$avg": "$ingest_bitrate"},
+			"avg_egress_bitrate": bson.M{"$avg": "$egress_bitrate"},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := s.metricCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []model.StreamMetricPoint{}
+	err = cursor.All(ctx, &points)
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (s OmeService) CreateNode(ctx context.Context, model *model.OmeNode) (*model.OmeNode, error) {
+	model.Id = primitive.NewObjectID()
+	currentTime := time.Now()
+	model.HealthState = "unknown"
+	model.CreatedAt = currentTime
+	model.UpdatedAt = currentTime
+
+	_, err := s.nodeCollection.InsertOne(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	return model, s.SyncNodeServers(ctx)
+}
+
+func (s OmeService) ListNodes(ctx context.Context) ([]model.OmeNode, error) {
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cursor, err := s.nodeCollection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]model.OmeNode, 0)
+	if err := cursor.All(ctx, &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func (s OmeService) FindNodeById(ctx context.Context, id primitive.ObjectID) (*model.OmeNode, error) {
+	var result model.OmeNode
+
+	err := s.nodeCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s OmeService) FindNodeByIpAddress(ctx context.Context, ip string) (*model.OmeNode, error) {
+	var result model.OmeNode
+
+	err := s.nodeCollection.FindOne(ctx, bson.M{"ip_address": ip}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s OmeService) UpdateNodeByID(ctx context.Context, id primitive.ObjectID, update bson.M) (*model.OmeNode, error) {
+	update["updated_at"] = time.Now()
+
+	result, err := s.nodeCollection.UpdateByID(ctx, id, bson.M{"$set": update})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+	if err := s.SyncNodeServers(ctx); err != nil {
+		return nil, err
+	}
+	return s.FindNodeById(ctx, id)
+}
+
+func (s OmeService) DeleteNode(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	result, err := s.nodeCollection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, s.SyncNodeServers(ctx)
+}
+
+// SyncNodeServers hands the API URL and token of every registered node to the OME HTTP client.
+func (s OmeService) SyncNodeServers(ctx context.Context) error {
+	nodes, err := s.ListNodes(ctx)
+	if err != nil {
+		return err
+	}
+	servers := make(map[string]http_clients.OmeServer, len(nodes))
+	for _, node := range nodes {
+		servers[node.IpAddress] = http_clients.OmeServer{
+			ApiUrl:   node.ApiUrl,
+			ApiToken: node.ApiToken,
+		}
+	}
+	s.omeHttpClient.SetServers(servers)
+	return nil
+}
+
+// PlaceStream picks a node for a new stream with the configured placement strategy.
+// It returns nil when no nodes are registered, in which case the single
+// OME_SERVER_BASE_URL origin is used.
+func (s OmeService) PlaceStream(ctx context.Context, request PlacementRequest) (*model.OmeNode, error) {
+	nodes, err := s.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	// Streams placed recently count as load before they go live, so a burst of creates is spread out.
+	reservedSince := time.Now().Add(-placementReservation)
+	candidates := make([]NodeCandidate, 0, len(nodes))
+	for _, node := range nodes {
+		if node.HealthState == "down" {
+			continue
+		}
+		liveCount, err := s.streamCollection.CountDocuments(ctx, bson.M{
+			"node_id": node.Id,
+			"$or": []bson.M{
+				{"status": "opening"},
+				{"status": "initiated", "created_at": bson.M{"$gte": reservedSince}},
+			},
+			"deleted_at": nil,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if node.Capacity > 0 && int(liveCount) >= node.Capacity {
+			continue
+		}
+		candidates = append(candidates, NodeCandidate{Node: node, LiveCount: int(liveCount)})
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoNodeAvailable
+	}
+
+	strategy, ok := placementStrategies[config.AppConfig.OME_PLACEMENT_STRATEGY]
+	if !ok {
+		strategy = LeastLoadedStrategy{}
+	}
+	node := strategy.Place(request, candidates)
+	return &node, nil
+}
+
+// GetWhipUrl returns the WHIP ingest URL of a stream on its node, or on OME_SERVER_BASE_URL
+// when the stream isn't placed on a registered node.
+func (s OmeService) GetWhipUrl(ctx context.Context, stream *model.Stream) (string, error) {
+	urls, err := s.GetStreamUrls(ctx, stream)
+	if err != nil {
+		return "", err
+	}
+	return urls.Ingest.Whip, nil
+}
+
+// GetBackupWhipUrl returns the WHIP ingest URL of a stream's backup encoder, which must publish
+// to the same node as the primary.
+func (s OmeService) GetBackupWhipUrl(ctx context.Context, stream *model.Stream) (string, error) {
+	urls, err := s.GetStreamUrls(ctx, stream)
+	if err != nil {
+		return "", err
+	}
+	if urls.BackupIngest == nil {
+		return "", nil
+	}
+	return urls.BackupIngest.Whip, nil
+}
+
+// ResolveOriginNode maps a webhook to the origin server IP and, when the origin is registered, its
+// node. The X-Forwarded-For header is client-supplied and API calls to unknown IPs carry the default
+// token, so the forwarded IP is only trusted when it names a registered node. Otherwise the peer IP
+// of the connection is used.
+func (s OmeService) ResolveOriginNode(ctx context.Context, forwardedFor string, peerIp string) (string, *model.OmeNode, error) {
+	forwardedIp := strings.TrimSpace(strings.Split(forwardedFor, ",")[0])
+	if forwardedIp != "" && forwardedIp != peerIp {
+		node, err := s.FindNodeByIpAddress(ctx, forwardedIp)
+		if err != nil {
+			return "", nil, err
+		}
+		if node != nil {
+			return forwardedIp, node, nil
+		}
+		fmt.Printf("[audit] ignoring X-Forwarded-For %s of unregistered node, using peer ip %s\n", forwardedIp, peerIp)
+	}
+
+	node, err := s.FindNodeByIpAddress(ctx, peerIp)
+	if err != nil {
+		return peerIp, nil, err
+	}
+	return peerIp, node, nil
+}
+
+// RecordNodeHealthCheck stores the outcome of a health probe and derives the node's health state.
+// A node is degraded when it answers slowly or has started failing, and down once it failed
+// NODE_DOWN_AFTER probes in a row.
+func (s OmeService) RecordNodeHealthCheck(ctx context.Context, node *model.OmeNode, latency time.Duration, checkErr error) (*model.OmeNode, error) {
+	update := bson.M{
+		"latency_ms":      latency.Milliseconds(),
+		"last_checked_at": time.Now(),
+	}
+
+	if checkErr != nil {
+		failures := node.ConsecutiveFailures + 1
+		update["consecutive_failures"] = failures
+		update["last_error"] = checkErr.Error()
+		// A node is only ever marked down after failing, even with NODE_DOWN_AFTER misconfigured.
+		if failures >= max(config.AppConfig.NODE_DOWN_AFTER, 1) {
+			update["health_state"] = "down"
+		} else {
+			update["health_state"] = "degraded"
+		}
+	} else {
+		update["consecutive_failures"] = 0
+		update["last_error"] = ""
+		if latency >= config.AppConfig.NODE_DEGRADED_LATENCY {
+			update["health_state"] = "degraded"
+		} else {
+			update["health_state"] = "healthy"
+		}
+	}
+
+	result, err := s.nodeCollection.UpdateByID(ctx, node.Id, bson.M{"$set": update})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+	return s.FindNodeById(ctx, node.Id)
+}
+
+// EnsureServerAvailable returns ErrNodeDown when the OME server at ip is a registered node
+// that the health monitor has marked down.
+func (s OmeService) EnsureServerAvailable(ctx context.Context, ip string) error {
+	node, err := s.FindNodeByIpAddress(ctx, ip)
+	if err != nil {
+		return err
+	}
+	if node != nil && node.HealthState == "down" {
+		return ErrNodeDown
+	}
+	return nil
+}
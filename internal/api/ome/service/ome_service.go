@@ -2,29 +2,76 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
 	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/storage"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var ErrStreamNotStopped = errors.New("stream is not ended or disabled")
+
 type OmeService struct {
-	streamCollection *mongo.Collection
-	pushCollection   *mongo.Collection
+	streamCollection        *mongo.Collection
+	pushCollection          *mongo.Collection
+	viewerSessionCollection *mongo.Collection
+	streamSessionCollection *mongo.Collection
+	idempotencyCollection   *mongo.Collection
+	nodeCollection          *mongo.Collection
+	metricCollection        *mongo.Collection
+	recordingCollection     *mongo.Collection
+	vodAssetCollection      *mongo.Collection
+	scheduleCollection      *mongo.Collection
+	channelCollection       *mongo.Collection
+	statsCache              *statsCache
+	omeHttpClient           *http_clients.OmeHTTPClient
+	pushStore               pushStore
+	pushClient              pushClient
 }
 
-func NewOmeService(db *mongo.Database) *OmeService {
+func NewOmeService(db *mongo.Database, omeHttpClient *http_clients.OmeHTTPClient) *OmeService {
 	streamCollection := db.Collection("streams")
 	pushCollection := db.Collection("pushes")
+	viewerSessionCollection := db.Collection("viewer_sessions")
+	streamSessionCollection := db.Collection("stream_sessions")
+	idempotencyCollection := db.Collection("idempotency_keys")
+	nodeCollection := db.Collection("ome_nodes")
+	metricCollection := db.Collection("stream_metrics")
+	recordingCollection := db.Collection("recordings")
+	vodAssetCollection := db.Collection("vod_assets")
+	scheduleCollection := db.Collection("schedules")
+	channelCollection := db.Collection("channels")
 
-	return &OmeService{
-		streamCollection: streamCollection,
-		pushCollection:   pushCollection,
+	service := &OmeService{
+		streamCollection:        streamCollection,
+		pushCollection:          pushCollection,
+		viewerSessionCollection: viewerSessionCollection,
+		streamSessionCollection: streamSessionCollection,
+		idempotencyCollection:   idempotencyCollection,
+		nodeCollection:          nodeCollection,
+		metricCollection:        metricCollection,
+		recordingCollection:     recordingCollection,
+		vodAssetCollection:      vodAssetCollection,
+		scheduleCollection:      scheduleCollection,
+		channelCollection:       channelCollection,
+		statsCache:              newStatsCache(),
+		omeHttpClient:           omeHttpClient,
+		pushClient:              omeHttpClient,
 	}
+	service.pushStore = service
+	return service
 }
 
 func (s OmeService) GetStreamName(urlStr string) string {
@@ -35,17 +82,112 @@ func (s OmeService) GetStreamName(urlStr string) string {
 		return ""
 	}
 
-	// Get path segments and return the last one
-	path := parsedUrl.Path // e.g., "/app/test"
+	// Get path segments and return the one after the app name
+	path := parsedUrl.Path // e.g., "/app/test" or "/app/test/llhls.m3u8"
 	// Remove leading slash and split by '/'
 	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(segments) > 1 {
+		return segments[1] // Returns "test"
+	}
 	if len(segments) > 0 {
-		return segments[len(segments)-1] // Returns "test"
+		return segments[len(segments)-1]
 	}
 
 	return ""
 }
 
+// VerifyWebhookSignature checks the X-OME-Signature header sent by OME AdmissionWebhooks.
+// OME signs the raw request body with HMAC-SHA1 and encodes the digest as URL-safe base64.
+func (s OmeService) VerifyWebhookSignature(body []byte, signature string, secret string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	for _, encoding := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding} {
+		decoded, err := encoding.DecodeString(signature)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPublishAdmission decides whether a publisher may open the given stream.
+// Unknown, ended and disabled streams are denied. A second publisher on a live stream
+// is denied unless takeover is enabled in config.
+func (s OmeService) CheckPublishAdmission(ctx context.Context, streamName string, requestUrl string) (*model.Stream, model.OmeWebhookResponse, error) {
+	objId, err := primitive.ObjectIDFromHex(streamName)
+	if err != nil {
+		return nil, model.OmeWebhookResponse{Allowed: false, Reason: "unknown stream"}, nil
+	}
+
+	stream, err := s.FindStreamById(ctx, objId)
+	if err != nil {
+		return nil, model.OmeWebhookResponse{Allowed: false, Reason: "internal error"}, err
+	}
+	if stream == nil {
+		return nil, model.OmeWebhookResponse{Allowed: false, Reason: "unknown stream"}, nil
+	}
+
+	switch stream.Status {
+	case "ended":
+		return stream, model.OmeWebhookResponse{Allowed: false, Reason: "stream has ended"}, nil
+	case "disabled":
+		return stream, model.OmeWebhookResponse{Allowed: false, Reason: "stream is disabled"}, nil
+	case "opening":
+		if !config.AppConfig.OME_ALLOW_TAKEOVER {
+			return stream, model.OmeWebhookResponse{Allowed: false, Reason: "stream is already live"}, nil
+		}
+	}
+
+	return stream, s.BuildAdmissionResponse(stream, requestUrl), nil
+}
+
+// BuildAdmissionResponse allows a publish for the given stream, capping the session
+// length and redirecting to the stream's app when it differs from the requested one.
+func (s OmeService) BuildAdmissionResponse(stream *model.Stream, requestUrl string) model.OmeWebhookResponse {
+	response := model.OmeWebhookResponse{
+		Allowed: true,
+	}
+	if stream == nil {
+		return response
+	}
+	response.Lifetime = stream.MaxSessionMs
+	response.NewUrl = s.RewritePublishUrl(requestUrl, stream)
+	return response
+}
+
+// GetStreamApp returns the OME application a stream is published to.
+func (s OmeService) GetStreamApp(stream *model.Stream) string {
+	if stream.App != "" {
+		return stream.App
+	}
+	return config.AppConfig.OME_APP_NAME
+}
+
+// RewritePublishUrl returns the publish URL moved to the stream's app, or an empty
+// string when no rewrite is needed. Only the app is redirected: all streams live on the
+// default vhost, so the host of the URL, which OME resolves the vhost from, is left as is.
+func (s OmeService) RewritePublishUrl(requestUrl string, stream *model.Stream) string {
+	targetApp := s.GetStreamApp(stream)
+
+	parsedUrl, err := url.Parse(requestUrl)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(strings.TrimPrefix(parsedUrl.Path, "/"), "/")
+	if len(segments) < 2 || segments[0] == targetApp {
+		return ""
+	}
+	segments[0] = targetApp
+	parsedUrl.Path = "/" + strings.Join(segments, "/")
+	return parsedUrl.String()
+}
+
 func (s OmeService) CreateStream(
 	ctx context.Context, model *model.Stream) (*model.Stream, error) {
 	model.Id = primitive.NewObjectID()
@@ -55,6 +197,15 @@ func (s OmeService) CreateStream(
 
 	_, err := s.streamCollection.InsertOne(ctx, model)
 	if err != nil {
+		// A concurrent request created the same external_id first, collapse onto that stream.
+		if mongo.IsDuplicateKeyError(err) && model.ExternalId != "" {
+			existingStream, findErr := s.GetStreamByExternalId(ctx, model.ExternalId)
+			if findErr != nil || existingStream == nil {
+				// The duplicate is on another unique field, e.g. backup_ingest_name.
+				return nil, err
+			}
+			return existingStream, nil
+		}
 		return nil, err
 	}
 	return model, nil
@@ -63,7 +214,7 @@ func (s OmeService) CreateStream(
 func (s OmeService) GetStreamByExternalId(ctx context.Context, externalId string) (*model.Stream, error) {
 	var result model.Stream
 
-	filter := bson.M{"external_id": externalId}
+	filter := bson.M{"external_id": externalId, "deleted_at": nil}
 	err := s.streamCollection.FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -77,7 +228,7 @@ func (s OmeService) GetStreamByExternalId(ctx context.Context, externalId string
 func (s OmeService) FindStreamById(c context.Context, id primitive.ObjectID) (*model.Stream, error) {
 	var result model.Stream
 
-	filter := bson.M{"_id": id}
+	filter := bson.M{"_id": id, "deleted_at": nil}
 	err := s.streamCollection.FindOne(c, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -90,9 +241,9 @@ func (s OmeService) FindStreamById(c context.Context, id primitive.ObjectID) (*m
 func (s OmeService) UpdateStreamByID(ctx context.Context, id primitive.ObjectID, update bson.M) (*model.Stream, error) {
 	update["updated_at"] = time.Now()
 
-	result, err := s.streamCollection.UpdateByID(
+	result, err := s.streamCollection.UpdateOne(
 		ctx,
-		id,
+		bson.M{"_id": id, "deleted_at": nil},
 		bson.M{"$set": update},
 	)
 	if err != nil {
@@ -109,6 +260,39 @@ func (s OmeService) UpdateStreamByID(ctx context.Context, id primitive.ObjectID,
 	return updatedStream, nil
 }
 
+// UpdateLiveStreamStatus sets the publisher status of a stream unless it has been
+// ended or disabled, so a late closing event can't re-open a taken down stream.
+func (s OmeService) UpdateLiveStreamStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	_, err := s.streamCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "deleted_at": nil, "status": bson.M{"$nin": []string{"ended", "disabled"}}},
+		bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}},
+	)
+	return err
+}
+
+// EnableStream lets an ended or disabled stream be published again. It returns
+// ErrStreamNotStopped for any other status, so a live stream keeps its admission and push state.
+func (s OmeService) EnableStream(ctx context.Context, id primitive.ObjectID) (*model.Stream, error) {
+	result, err := s.streamCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "deleted_at": nil, "status": bson.M{"$in": []string{"ended", "disabled"}}},
+		bson.M{"$set": bson.M{"status": "initiated", "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := s.FindStreamById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if stream != nil && result.MatchedCount == 0 {
+		return nil, ErrStreamNotStopped
+	}
+	return stream, nil
+}
+
 func (s OmeService) CreatePush(
 	ctx context.Context, model *model.Push) (*model.Push, error) {
 	model.Id = primitive.NewObjectID()
@@ -123,10 +307,67 @@ func (s OmeService) CreatePush(
 	return model, nil
 }
 
-func (s OmeService) FindPushByStreamIdAndStatus(ctx context.Context, id primitive.ObjectID, status string) (*model.Push, error) {
+func (s OmeService) FindPushesByStreamIdAndStatus(ctx context.Context, id primitive.ObjectID, status string) ([]model.Push, error) {
+	filter := bson.M{"stream_id": id, "status": status, "deleted_at": nil}
+	cursor, err := s.pushCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	pushes := make([]model.Push, 0)
+	if err := cursor.All(ctx, &pushes); err != nil {
+		return nil, err
+	}
+	return pushes, nil
+}
+
+// FindPushesToReconcile returns the active pushes on the given OME server along with the pending
+// ones created before pendingCreatedBefore. Younger pending pushes may still be starting on OME.
+func (s OmeService) FindPushesToReconcile(ctx context.Context, ip string, pendingCreatedBefore time.Time) ([]model.Push, error) {
+	filter := bson.M{
+		"server_ip_address": ip,
+		"$or": []bson.M{
+			{"status": "active"},
+			{"status": "pending", "created_at": bson.M{"$lt": pendingCreatedBefore}},
+		},
+		"deleted_at": nil,
+	}
+	cursor, err := s.pushCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	pushes := make([]model.Push, 0)
+	if err := cursor.All(ctx, &pushes); err != nil {
+		return nil, err
+	}
+	return pushes, nil
+}
+
+func (s OmeService) ListPushesByStreamId(ctx context.Context, id primitive.ObjectID) ([]model.Push, error) {
+	filter := bson.M{"stream_id": id, "deleted_at": nil}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := s.pushCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	pushes := make([]model.Push, 0)
+	if err := cursor.All(ctx, &pushes); err != nil {
+		return nil, err
+	}
+	return pushes, nil
+}
+
+// FindActivePushByStreamIdAndUrl finds a running push of the stream to destinationUrl, including one
+// waiting for a retry. Pushes created before the url field was introduced only carry rtmp_url, so
+// both are matched.
+func (s OmeService) FindActivePushByStreamIdAndUrl(ctx context.Context, id primitive.ObjectID, destinationUrl string) (*model.Push, error) {
 	var result model.Push
 
-	filter := bson.M{"stream_id": id, "status": status}
+	filter := bson.M{
+		"stream_id":  id,
+		"$or":        []bson.M{{"url": destinationUrl}, {"rtmp_url": destinationUrl}},
+		"status":     bson.M{"$in": []string{"pending", "active", "retrying"}},
+		"deleted_at": nil,
+	}
 	err := s.pushCollection.FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -136,12 +377,13 @@ func (s OmeService) FindPushByStreamIdAndStatus(ctx context.Context, id primitiv
 	}
 	return &result, nil
 }
+
 func (s OmeService) UpdatePushByID(ctx context.Context, id primitive.ObjectID, update bson.M) (*model.Push, error) {
 	update["updated_at"] = time.Now()
 
-	result, err := s.pushCollection.UpdateByID(
+	result, err := s.pushCollection.UpdateOne(
 		ctx,
-		id,
+		bson.M{"_id": id, "deleted_at": nil},
 		bson.M{"$set": update},
 	)
 	if err != nil {
@@ -160,7 +402,7 @@ func (s OmeService) UpdatePushByID(ctx context.Context, id primitive.ObjectID, u
 func (s OmeService) FindPushById(ctx context.Context, id primitive.ObjectID) (*model.Push, error) {
 	var result model.Push
 
-	filter := bson.M{"_id": id}
+	filter := bson.M{"_id": id, "deleted_at": nil}
 	err := s.pushCollection.FindOne(ctx, filter).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -171,3 +413,136 @@ func (s OmeService) FindPushById(ctx context.Context, id primitive.ObjectID) (*m
 	return &result, nil
 
 }
+
+// SoftDeleteStream marks a stream and its pushes as deleted. Deleted documents are hidden
+// from all queries and hard-deleted by PurgeDeletedStreams after the retention period.
+func (s OmeService) SoftDeleteStream(ctx context.Context, id primitive.ObjectID) error {
+	currentTime := time.Now()
+	update := bson.M{"$set": bson.M{"deleted_at": currentTime, "updated_at": currentTime}}
+
+	for _, collection := range []*mongo.Collection{s.pushCollection, s.scheduleCollection} {
+		_, err := collection.UpdateMany(ctx, bson.M{"stream_id": id, "deleted_at": nil}, update)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := s.streamCollection.UpdateOne(ctx, bson.M{"_id": id, "deleted_at": nil}, update)
+	return err
+}
+
+// PurgeDeletedStreams hard-deletes streams soft-deleted before the given time along with
+// their pushes, schedules, session history, metrics, recordings and VOD assets. The stored VOD
+// files are deleted too, and so are channels that play the streams or their assets, since they
+// can't be played any more. It returns the number of purged streams.
+func (s OmeService) PurgeDeletedStreams(ctx context.Context, deletedBefore time.Time, store storage.Storage) (int64, error) {
+	filter := bson.M{"deleted_at": bson.M{"$ne": nil, "$lt": deletedBefore}}
+	cursor, err := s.streamCollection.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	var streams []model.Stream
+	if err := cursor.All(ctx, &streams); err != nil {
+		return 0, err
+	}
+	if len(streams) == 0 {
+		return 0, nil
+	}
+
+	streamIds := make([]primitive.ObjectID, 0, len(streams))
+	for _, stream := range streams {
+		streamIds = append(streamIds, stream.Id)
+	}
+	byStreamId := bson.M{"stream_id": bson.M{"$in": streamIds}}
+
+	assetIds, err := s.purgeVodAssetFiles(ctx, byStreamId, store)
+	if err != nil {
+		return 0, err
+	}
+	err = s.purgeChannelsPlaying(ctx, streamIds, assetIds)
+	if err != nil {
+		return 0, err
+	}
+
+	collections := []*mongo.Collection{
+		s.pushCollection,
+		s.streamSessionCollection,
+		s.viewerSessionCollection,
+		s.scheduleCollection,
+		s.metricCollection,
+		s.recordingCollection,
+		s.vodAssetCollection,
+	}
+	for _, collection := range collections {
+		_, err := collection.DeleteMany(ctx, byStreamId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	result, err := s.streamCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": streamIds}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// purgeVodAssetFiles deletes the stored files of the VOD assets matching filter and returns the
+// IDs of the assets.
+func (s OmeService) purgeVodAssetFiles(ctx context.Context, filter bson.M, store storage.Storage) ([]primitive.ObjectID, error) {
+	cursor, err := s.vodAssetCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var assets []model.VodAsset
+	if err := cursor.All(ctx, &assets); err != nil {
+		return nil, err
+	}
+
+	assetIds := make([]primitive.ObjectID, 0, len(assets))
+	for _, asset := range assets {
+		assetIds = append(assetIds, asset.Id)
+		if asset.StorageKey == "" || asset.Storage != store.Name() {
+			continue
+		}
+		err := store.Delete(ctx, asset.StorageKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete vod asset %s: %w", asset.Id.Hex(), err)
+		}
+	}
+	return assetIds, nil
+}
+
+// purgeChannelsPlaying removes the channels with items playing any of the given streams or VOD
+// assets from OME and hard-deletes them.
+func (s OmeService) purgeChannelsPlaying(ctx context.Context, streamIds []primitive.ObjectID, assetIds []primitive.ObjectID) error {
+	cursor, err := s.channelCollection.Find(ctx, bson.M{"$or": []bson.M{
+		{"programs.items.stream_id": bson.M{"$in": streamIds}},
+		{"programs.items.vod_asset_id": bson.M{"$in": assetIds}},
+		{"fallback.stream_id": bson.M{"$in": streamIds}},
+		{"fallback.vod_asset_id": bson.M{"$in": assetIds}},
+	}})
+	if err != nil {
+		return err
+	}
+	var channels []model.Channel
+	if err := cursor.All(ctx, &channels); err != nil {
+		return err
+	}
+
+	channelIds := make([]primitive.ObjectID, 0, len(channels))
+	for _, channel := range channels {
+		channelIds = append(channelIds, channel.Id)
+		if channel.DeletedAt != nil {
+			continue
+		}
+		err := s.omeHttpClient.DeleteScheduledChannel(channel.ServerIpAddress, channel.App, channel.Id.Hex())
+		if err != nil {
+			return fmt.Errorf("failed to delete channel %s: %w", channel.Id.Hex(), err)
+		}
+	}
+	if len(channelIds) == 0 {
+		return nil
+	}
+	_, err = s.channelCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": channelIds}})
+	return err
+}
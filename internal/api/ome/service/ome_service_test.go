@@ -0,0 +1,93 @@
+package service
+
+import "testing"
+
+// Fixture bodies signed with fixtureWebhookSecret, the signatures being OME's URL-safe base64
+// HMAC-SHA1 of the raw body.
+const (
+	fixtureWebhookSecret = "fixture-secret"
+
+	fixtureOpeningBody      = `{"client":{"address":"211.233.58.86","port":29291},"request":{"direction":"incoming","protocol":"webrtc","url":"ws://ome.example.com:3333/app/65f1c0ffee0000000000abcd","time":"2024-03-13T08:41:12.000Z","status":"opening"}}`
+	fixtureOpeningSignature = "7d5364aAyTcKza4WpCdUGCrxP7k"
+
+	fixtureClosingBody      = `{"request":{"direction":"incoming","status":"closing","url":"rtmp://ome.example.com:1935/app/stream0"}}`
+	fixtureClosingSignature = "NpmTQFnB_Njjzm_BivMOy2EjtDM"
+	// fixtureClosingStdSignature is the same digest in standard base64, which OME never sends.
+	fixtureClosingStdSignature = "NpmTQFnB/Njjzm/BivMOy2EjtDM="
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		secret    string
+		want      bool
+	}{
+		{
+			name:      "valid signature",
+			body:      fixtureOpeningBody,
+			signature: fixtureOpeningSignature,
+			secret:    fixtureWebhookSecret,
+			want:      true,
+		},
+		{
+			name:      "valid signature with url-safe characters",
+			body:      fixtureClosingBody,
+			signature: fixtureClosingSignature,
+			secret:    fixtureWebhookSecret,
+			want:      true,
+		},
+		{
+			name:      "valid padded signature",
+			body:      fixtureOpeningBody,
+			signature: fixtureOpeningSignature + "=",
+			secret:    fixtureWebhookSecret,
+			want:      true,
+		},
+		{
+			name:      "tampered body",
+			body:      `{"request":{"direction":"incoming","status":"closing","url":"rtmp://ome.example.com:1935/app/stream1"}}`,
+			signature: fixtureClosingSignature,
+			secret:    fixtureWebhookSecret,
+			want:      false,
+		},
+		{
+			name:      "wrong secret",
+			body:      fixtureOpeningBody,
+			signature: fixtureOpeningSignature,
+			secret:    "other-secret",
+			want:      false,
+		},
+		{
+			name:      "missing header",
+			body:      fixtureOpeningBody,
+			signature: "",
+			secret:    fixtureWebhookSecret,
+			want:      false,
+		},
+		{
+			name:      "missing secret",
+			body:      fixtureOpeningBody,
+			signature: fixtureOpeningSignature,
+			secret:    "",
+			want:      false,
+		},
+		{
+			name:      "non url-safe base64 header",
+			body:      fixtureClosingBody,
+			signature: fixtureClosingStdSignature,
+			secret:    fixtureWebhookSecret,
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OmeService{}.VerifyWebhookSignature([]byte(tt.body), tt.signature, tt.secret)
+			if got != tt.want {
+				t.Errorf("VerifyWebhookSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"hash/fnv"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+)
+
+// unlimitedNodeCapacity is the capacity assumed for nodes without one when comparing load.
+const unlimitedNodeCapacity = 100
+
+// placementReservation is how long a placed stream that hasn't gone live counts towards its node's load.
+const placementReservation = 5 * time.Minute
+
+var (
+	ErrNoNodeAvailable = errors.New("no OME node available for placement")
+	ErrNodeDown        = errors.New("OME node is down")
+)
+
+// PlacementRequest carries what a strategy may use to pick a node for a new stream.
+type PlacementRequest struct {
+	ExternalId string
+	Region     string
+}
+
+// NodeCandidate is a node eligible for placement together with its current number of live streams,
+// including streams placed within placementReservation that haven't gone live yet.
+type NodeCandidate struct {
+	Node      model.OmeNode
+	LiveCount int
+}
+
+// PlacementStrategy picks the node a new stream is placed on. Candidates are never empty and
+// never include nodes that are at capacity.
+type PlacementStrategy interface {
+	Place(request PlacementRequest, candidates []NodeCandidate) model.OmeNode
+}
+
+// placementStrategies maps the OME_PLACEMENT_STRATEGY config value to its implementation.
+var placementStrategies = map[string]PlacementStrategy{
+	"least_loaded": LeastLoadedStrategy{},
+	"region":       RegionAffinityStrategy{},
+	"sticky":       StickyStrategy{},
+}
+
+// LeastLoadedStrategy picks the node with the lowest share of its capacity in use.
+type LeastLoadedStrategy struct{}
+
+func (LeastLoadedStrategy) Place(request PlacementRequest, candidates []NodeCandidate) model.OmeNode {
+	best := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if loadRatio(candidate) < loadRatio(best) {
+			best = candidate
+		}
+	}
+	return best.Node
+}
+
+func loadRatio(candidate NodeCandidate) float64 {
+	capacity := candidate.Node.Capacity
+	if capacity <= 0 {
+		capacity = unlimitedNodeCapacity
+	}
+	return float64(candidate.LiveCount) / float64(capacity)
+}
+
+// RegionAffinityStrategy prefers the least loaded node in the requested region and falls back
+// to the least loaded node overall.
+type RegionAffinityStrategy struct{}
+
+func (RegionAffinityStrategy) Place(request PlacementRequest, candidates []NodeCandidate) model.OmeNode {
+	inRegion := make([]NodeCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if request.Region != "" && candidate.Node.Region == request.Region {
+			inRegion = append(inRegion, candidate)
+		}
+	}
+	if len(inRegion) > 0 {
+		return LeastLoadedStrategy{}.Place(request, inRegion)
+	}
+	return LeastLoadedStrategy{}.Place(request, candidates)
+}
+
+// StickyStrategy always maps the same external ID to the same node while that node is available,
+// using rendezvous hashing so only streams of a removed node move.
+type StickyStrategy struct{}
+
+func (StickyStrategy) Place(request PlacementRequest, candidates []NodeCandidate) model.OmeNode {
+	best := candidates[0]
+	var bestScore uint64
+	for i, candidate := range candidates {
+		hash := fnv.New64a()
+		hash.Write([]byte(request.ExternalId))
+		hash.Write([]byte(candidate.Node.Id.Hex()))
+		score := hash.Sum64()
+		if i == 0 || score > bestScore {
+			best = candidate
+			bestScore = score
+		}
+	}
+	return best.Node
+}
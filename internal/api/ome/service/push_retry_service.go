@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultRetryInitialBackoff = 2 * time.Second
+	defaultRetryMaxBackoff     = time.Minute
+	// pushStableAfter is how long a recovered push must stay up before a new failure starts counting
+	// retry attempts from zero again.
+	pushStableAfter = time.Minute
+)
+
+// MarkPushFailed records a push failure. Pushes with a retry policy that still has attempts left
+// are moved to "retrying" with the next attempt scheduled, every other push becomes "failed".
+// A push that drops again within pushStableAfter of recovering continues its previous failure, so
+// its attempts keep counting towards the policy's limits. Only active and pending pushes are
+// updated, so a push stopped in the meantime isn't revived.
+func (s OmeService) MarkPushFailed(ctx context.Context, push *model.Push, reason string, failedAt time.Time) error {
+	currentTime := time.Now()
+	set := bson.M{
+		"failure_reason": reason,
+		"updated_at":     currentTime,
+	}
+
+	failedSince := failedAt
+	retryCount := 0
+	if push.FailedSince != nil && (push.RecoveredAt == nil || failedAt.Sub(*push.RecoveredAt) < pushStableAfter) {
+		failedSince = *push.FailedSince
+		retryCount = push.RetryCount
+	}
+	failedPush := *push
+	failedPush.RetryCount = retryCount
+	set["failed_since"] = failedSince
+	set["retry_count"] = retryCount
+
+	if s.canRetryPush(&failedPush, failedSince, failedAt) {
+		set["status"] = "retrying"
+		set["next_retry_at"] = failedAt.Add(s.PushRetryBackoff(push.RetryPolicy, retryCount))
+	} else {
+		set["status"] = "failed"
+		set["next_retry_at"] = nil
+	}
+
+	_, err := s.pushCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": push.Id, "status": bson.M{"$in": []string{"active", "pending"}}, "deleted_at": nil},
+		bson.M{"$set": set},
+	)
+	return err
+}
+
+// RecordPushRetryAttempt stores the outcome of a retry attempt. A successful attempt puts the push
+// back to "active" and records when it recovered; its retry count is kept until it has stayed up
+// for pushStableAfter. The update only applies while the push is still "retrying", so a push
+// removed in the meantime isn't revived; in that case nil is returned.
+func (s OmeService) RecordPushRetryAttempt(ctx context.Context, push *model.Push, attemptErr error) (*model.Push, error) {
+	currentTime := time.Now()
+	attempt := model.PushRetryAttempt{
+		Attempt:     push.RetryCount + 1,
+		AttemptedAt: currentTime,
+	}
+	push.RetryCount = attempt.Attempt
+
+	set := bson.M{
+		"updated_at":  currentTime,
+		"retry_count": push.RetryCount,
+	}
+	if attemptErr == nil {
+		set["status"] = "active"
+		set["server_ip_address"] = push.ServerIpAddress
+		set["app"] = push.App
+		set["recovered_at"] = currentTime
+		set["next_retry_at"] = nil
+		set["failure_reason"] = ""
+	} else {
+		attempt.Error = attemptErr.Error()
+
+		failedSince := currentTime
+		if push.FailedSince != nil {
+			failedSince = *push.FailedSince
+		}
+		set["failure_reason"] = attempt.Error
+		if s.canRetryPush(push, failedSince, currentTime) {
+			set["next_retry_at"] = currentTime.Add(s.PushRetryBackoff(push.RetryPolicy, push.RetryCount))
+		} else {
+			set["status"] = "failed"
+			set["next_retry_at"] = nil
+		}
+	}
+
+	result, err := s.pushCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": push.Id, "status": "retrying", "deleted_at": nil},
+		bson.M{
+			"$set":  set,
+			"$push": bson.M{"retry_attempts": attempt},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+	return s.FindPushById(ctx, push.Id)
+}
+
+// FindPushesDueForRetry returns "retrying" pushes whose next attempt is due.
+func (s OmeService) FindPushesDueForRetry(ctx context.Context, now time.Time) ([]model.Push, error) {
+	filter := bson.M{
+		"status":        "retrying",
+		"next_retry_at": bson.M{"$lte": now},
+		"deleted_at":    nil,
+	}
+	cursor, err := s.pushCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	pushes := make([]model.Push, 0)
+	if err := cursor.All(ctx, &pushes); err != nil {
+		return nil, err
+	}
+	return pushes, nil
+}
+
+// PushRetryBackoff returns the delay before the attempt following retryCount previous attempts.
+func (s OmeService) PushRetryBackoff(policy *model.PushRetryPolicy, retryCount int) time.Duration {
+	initialBackoff := defaultRetryInitialBackoff
+	maxBackoff := defaultRetryMaxBackoff
+	if policy != nil && policy.InitialBackoffMs > 0 {
+		initialBackoff = time.Duration(policy.InitialBackoffMs) * time.Millisecond
+	}
+	if policy != nil && policy.MaxBackoffMs > 0 {
+		maxBackoff = time.Duration(policy.MaxBackoffMs) * time.Millisecond
+	}
+
+	backoff := initialBackoff
+	for i := 0; i < retryCount && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+func (s OmeService) canRetryPush(push *model.Push, failedSince time.Time, now time.Time) bool {
+	policy := push.RetryPolicy
+	if policy == nil || push.RetryCount >= policy.MaxAttempts {
+		return false
+	}
+	if policy.GiveUpAfterMs > 0 && now.Sub(failedSince) >= time.Duration(policy.GiveUpAfterMs)*time.Millisecond {
+		return false
+	}
+	return true
+}
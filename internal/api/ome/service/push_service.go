@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrDuplicatePushTarget = errors.New("stream is already pushing to this destination")
+
+// pushClient is the part of the OME API that starts and stops pushes.
+type pushClient interface {
+	StartPush(ip string, app string, streamName string, pushId string, options http_clients.PushOptions) (*http_clients.StartPushResponse, error)
+	StopPush(ip string, app string, pushId string) error
+}
+
+// pushStore keeps the push records and the node state StartPush and StopPush depend on.
+type pushStore interface {
+	FindActivePushByStreamIdAndUrl(ctx context.Context, id primitive.ObjectID, destinationUrl string) (*model.Push, error)
+	CreatePush(ctx context.Context, push *model.Push) (*model.Push, error)
+	UpdatePushByID(ctx context.Context, id primitive.ObjectID, update bson.M) (*model.Push, error)
+	EnsureServerAvailable(ctx context.Context, ip string) error
+}
+
+// StartPush starts a new push from the stream to the target alongside any pushes already running.
+//
+// It runs as a saga over Mongo and OME: the push is recorded as "pending", started on OME and then
+// committed as "active". When OME rejects the push the record is rolled back to "failed", and when
+// the final commit fails the push is stopped on OME again so the two never disagree.
+func (s OmeService) StartPush(ctx context.Context, stream *model.Stream, target *model.Push) (*model.Push, error) {
+	duplicatePush, err := s.pushStore.FindActivePushByStreamIdAndUrl(ctx, stream.Id, target.Url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing pushes: %w", err)
+	}
+	if duplicatePush != nil {
+		return nil, ErrDuplicatePushTarget
+	}
+
+	err = s.pushStore.EnsureServerAvailable(ctx, stream.ServerIpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	target.StreamId = stream.Id
+	target.ServerIpAddress = stream.ServerIpAddress
+	target.App = s.GetStreamApp(stream)
+	target.Status = "pending"
+	createdPush, err := s.pushStore.CreatePush(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create push record: %w", err)
+	}
+
+	_, err = s.pushClient.StartPush(
+		stream.ServerIpAddress,
+		createdPush.App,
+		s.GetPushSourceStreamName(s.GetStreamIngestName(stream)),
+		createdPush.Id.Hex(),
+		s.GetPushOptions(createdPush))
+	if err != nil {
+		_, rollbackErr := s.pushStore.UpdatePushByID(ctx, createdPush.Id, bson.M{
+			"status":         "failed",
+			"failure_reason": err.Error(),
+		})
+		if rollbackErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("failed to roll back push record: %w", rollbackErr))
+		}
+		return nil, err
+	}
+
+	activePush, err := s.pushStore.UpdatePushByID(ctx, createdPush.Id, bson.M{
+		"status": "active",
+	})
+	if err == nil && activePush == nil {
+		err = errors.New("push record no longer exists")
+	}
+	if err != nil {
+		commitErr := fmt.Errorf("failed to update push status: %w", err)
+		stopErr := s.pushClient.StopPush(stream.ServerIpAddress, createdPush.App, createdPush.Id.Hex())
+		if stopErr != nil {
+			return nil, errors.Join(commitErr, fmt.Errorf("failed to stop push on OME: %w", stopErr))
+		}
+		_, markErr := s.pushStore.UpdatePushByID(ctx, createdPush.Id, bson.M{
+			"status":         "failed",
+			"failure_reason": commitErr.Error(),
+		})
+		if markErr != nil {
+			return nil, errors.Join(commitErr, fmt.Errorf("failed to mark push failed: %w", markErr))
+		}
+		return nil, commitErr
+	}
+	return activePush, nil
+}
+
+// StopPush stops a single push on OME and marks it inactive. Pushes waiting for a retry
+// aren't running on OME, so they are only marked inactive to cancel the retry.
+func (s OmeService) StopPush(ctx context.Context, stream *model.Stream, push *model.Push) error {
+	if push.Status != "retrying" {
+		err := s.pushClient.StopPush(stream.ServerIpAddress, s.GetPushApp(push), push.Id.Hex())
+		if err != nil {
+			return err
+		}
+	}
+	_, err := s.pushStore.UpdatePushByID(ctx, push.Id, bson.M{
+		"status": "inactive",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update push status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errInsert = errors.New("insert failed")
+	errStart  = errors.New("start push failed")
+	errUpdate = errors.New("update failed")
+	errStop   = errors.New("stop push failed")
+	errMark   = errors.New("mark failed failed")
+)
+
+// fakePushStore keeps pushes in memory. updateErrs fails the UpdatePushByID calls in order, a nil
+// entry lets that call through. Setting deleteOnUpdate makes updates find no record.
+type fakePushStore struct {
+	pushes         map[primitive.ObjectID]*model.Push
+	duplicate      *model.Push
+	insertErr      error
+	updateErrs     []error
+	updateCalls    int
+	deleteOnUpdate bool
+}
+
+func newFakePushStore() *fakePushStore {
+	return &fakePushStore{pushes: map[primitive.ObjectID]*model.Push{}}
+}
+
+func (f *fakePushStore) FindActivePushByStreamIdAndUrl(ctx context.Context, id primitive.ObjectID, destinationUrl string) (*model.Push, error) {
+	return f.duplicate, nil
+}
+
+func (f *fakePushStore) CreatePush(ctx context.Context, push *model.Push) (*model.Push, error) {
+	if f.insertErr != nil {
+		return nil, f.insertErr
+	}
+	push.Id = primitive.NewObjectID()
+	stored := *push
+	f.pushes[push.Id] = &stored
+	return push, nil
+}
+
+func (f *fakePushStore) UpdatePushByID(ctx context.Context, id primitive.ObjectID, update bson.M) (*model.Push, error) {
+	call := f.updateCalls
+	f.updateCalls++
+	if call < len(f.updateErrs) && f.updateErrs[call] != nil {
+		return nil, f.updateErrs[call]
+	}
+	if f.deleteOnUpdate {
+		delete(f.pushes, id)
+	}
+	push, ok := f.pushes[id]
+	if !ok {
+		return nil, nil
+	}
+	if status, ok := update["status"].(string); ok {
+		push.Status = status
+	}
+	if reason, ok := update["failure_reason"].(string); ok {
+		push.FailureReason = reason
+	}
+	updated := *push
+	return &updated, nil
+}
+
+func (f *fakePushStore) EnsureServerAvailable(ctx context.Context, ip string) error {
+	return nil
+}
+
+// onlyPush returns the single push record the store holds, or nil.
+func (f *fakePushStore) onlyPush() *model.Push {
+	for _, push := range f.pushes {
+		return push
+	}
+	return nil
+}
+
+type fakePushClient struct {
+	startErr   error
+	stopErr    error
+	startCalls int
+	stopCalls  int
+}
+
+func (f *fakePushClient) StartPush(ip string, app string, streamName string, pushId string, options http_clients.PushOptions) (*http_clients.StartPushResponse, error) {
+	f.startCalls++
+	if f.startErr != nil {
+		return nil, f.startErr
+	}
+	return &http_clients.StartPushResponse{}, nil
+}
+
+func (f *fakePushClient) StopPush(ip string, app string, pushId string) error {
+	f.stopCalls++
+	return f.stopErr
+}
+
+func TestStartPush(t *testing.T) {
+	tests := []struct {
+		name           string
+		duplicate      bool
+		insertErr      error
+		startErr       error
+		updateErrs     []error
+		deleteOnUpdate bool
+		stopErr        error
+		wantErrs       []error
+		wantStatus     string // status of the stored record, "" when none was created
+		wantStarts     int
+		wantStops      int
+	}{
+		{
+			name:       "all steps succeed",
+			wantStatus: "active",
+			wantStarts: 1,
+		},
+		{
+			name:      "duplicate destination",
+			duplicate: true,
+			wantErrs:  []error{ErrDuplicatePushTarget},
+		},
+		{
+			name:      "insert fails",
+			insertErr: errInsert,
+			wantErrs:  []error{errInsert},
+		},
+		{
+			name:       "OME start fails",
+			startErr:   errStart,
+			wantErrs:   []error{errStart},
+			wantStatus: "failed",
+			wantStarts: 1,
+		},
+		{
+			name:       "OME start fails and rollback fails",
+			startErr:   errStart,
+			updateErrs: []error{errUpdate},
+			wantErrs:   []error{errStart, errUpdate},
+			wantStatus: "pending",
+			wantStarts: 1,
+		},
+		{
+			name:       "status update fails",
+			updateErrs: []error{errUpdate},
+			wantErrs:   []error{errUpdate},
+			wantStatus: "failed",
+			wantStarts: 1,
+			wantStops:  1,
+		},
+		{
+			name:           "record removed before status update",
+			deleteOnUpdate: true,
+			wantErrs:       []error{},
+			wantStarts:     1,
+			wantStops:      1,
+		},
+		{
+			name:       "status update fails and compensating stop fails",
+			updateErrs: []error{errUpdate},
+			stopErr:    errStop,
+			wantErrs:   []error{errUpdate, errStop},
+			wantStatus: "pending",
+			wantStarts: 1,
+			wantStops:  1,
+		},
+		{
+			name:       "status update fails and marking failed fails",
+			updateErrs: []error{errUpdate, errMark},
+			wantErrs:   []error{errUpdate, errMark},
+			wantStatus: "pending",
+			wantStarts: 1,
+			wantStops:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakePushStore()
+			store.insertErr = tt.insertErr
+			store.updateErrs = tt.updateErrs
+			store.deleteOnUpdate = tt.deleteOnUpdate
+			if tt.duplicate {
+				store.duplicate = &model.Push{Id: primitive.NewObjectID(), Status: "active"}
+			}
+			client := &fakePushClient{startErr: tt.startErr, stopErr: tt.stopErr}
+			s := OmeService{pushStore: store, pushClient: client}
+
+			stream := &model.Stream{Id: primitive.NewObjectID(), App: "app", ServerIpAddress: "10.0.0.1", Status: "opening"}
+			push, err := s.StartPush(context.Background(), stream, &model.Push{Protocol: "rtmp", Url: "rtmp://live.example.com/app"})
+
+			if tt.wantErrs == nil {
+				if err != nil {
+					t.Fatalf("StartPush() error = %v, want nil", err)
+				}
+				if push == nil || push.Status != "active" {
+					t.Fatalf("StartPush() = %+v, want an active push", push)
+				}
+			} else {
+				if err == nil {
+					t.Fatal("StartPush() error = nil, want an error")
+				}
+				for _, wantErr := range tt.wantErrs {
+					if !errors.Is(err, wantErr) {
+						t.Errorf("StartPush() error = %v, want it to wrap %v", err, wantErr)
+					}
+				}
+				if push != nil {
+					t.Errorf("StartPush() = %+v, want nil on error", push)
+				}
+			}
+
+			stored := store.onlyPush()
+			switch {
+			case tt.wantStatus == "" && stored != nil:
+				t.Errorf("stored push status = %s, want no record", stored.Status)
+			case tt.wantStatus != "" && stored == nil:
+				t.Errorf("no stored push, want status %s", tt.wantStatus)
+			case stored != nil && stored.Status != tt.wantStatus:
+				t.Errorf("stored push status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+			if client.startCalls != tt.wantStarts {
+				t.Errorf("OME StartPush calls = %d, want %d", client.startCalls, tt.wantStarts)
+			}
+			if client.stopCalls != tt.wantStops {
+				t.Errorf("OME StopPush calls = %d, want %d", client.stopCalls, tt.wantStops)
+			}
+		})
+	}
+}
+
+func TestStopPush(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		stopErr    error
+		wantErr    error
+		wantStatus string
+		wantStops  int
+	}{
+		{
+			name:       "active push is stopped on OME",
+			status:     "active",
+			wantStatus: "inactive",
+			wantStops:  1,
+		},
+		{
+			name:       "retrying push is only cancelled",
+			status:     "retrying",
+			wantStatus: "inactive",
+		},
+		{
+			name:       "OME stop fails",
+			status:     "active",
+			stopErr:    errStop,
+			wantErr:    errStop,
+			wantStatus: "active",
+			wantStops:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakePushStore()
+			push, _ := store.CreatePush(context.Background(), &model.Push{Status: tt.status})
+			client := &fakePushClient{stopErr: tt.stopErr}
+			s := OmeService{pushStore: store, pushClient: client}
+
+			err := s.StopPush(context.Background(), &model.Stream{ServerIpAddress: "10.0.0.1"}, push)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("StopPush() error = %v, want %v", err, tt.wantErr)
+			}
+			if status := store.onlyPush().Status; status != tt.wantStatus {
+				t.Errorf("stored push status = %s, want %s", status, tt.wantStatus)
+			}
+			if client.stopCalls != tt.wantStops {
+				t.Errorf("OME StopPush calls = %d, want %d", client.stopCalls, tt.wantStops)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+)
+
+// pushUrlSchemes lists the destination URL schemes OME accepts for each push protocol.
+var pushUrlSchemes = map[string][]string{
+	"rtmp":   {"rtmp", "rtmps"},
+	"srt":    {"srt"},
+	"mpegts": {"udp", "tcp"},
+}
+
+// ValidatePushUrl checks that destinationUrl is usable as a push target for the given protocol.
+func (s OmeService) ValidatePushUrl(protocol string, destinationUrl string) error {
+	schemes, ok := pushUrlSchemes[protocol]
+	if !ok {
+		return fmt.Errorf("unsupported push protocol: %s", protocol)
+	}
+
+	parsedUrl, err := url.Parse(destinationUrl)
+	if err != nil {
+		return fmt.Errorf("invalid push url: %w", err)
+	}
+	if parsedUrl.Hostname() == "" {
+		return fmt.Errorf("push url must have a host")
+	}
+
+	schemeAllowed := false
+	for _, scheme := range schemes {
+		if parsedUrl.Scheme == scheme {
+			schemeAllowed = true
+			break
+		}
+	}
+	if !schemeAllowed {
+		return fmt.Errorf("%s push url must use one of the schemes %v", protocol, schemes)
+	}
+
+	// SRT and MPEG-TS have no default port, so the destination must name one.
+	if protocol != "rtmp" && parsedUrl.Port() == "" {
+		return fmt.Errorf("%s push url must have a port", protocol)
+	}
+	return nil
+}
+
+// GetPushSourceStreamName returns the OME output stream that pushes are taken from.
+func (s OmeService) GetPushSourceStreamName(streamName string) string {
+	return streamName + config.AppConfig.OME_PUSH_SUFFIX
+}
+
+// GetPushApp returns the OME application a push is sent from. Pushes created before the app
+// was stored are on the default app.
+func (s OmeService) GetPushApp(push *model.Push) string {
+	if push.App != "" {
+		return push.App
+	}
+	return config.AppConfig.OME_APP_NAME
+}
+
+// GetPushOptions builds the OME push options stored on a push record.
+func (s OmeService) GetPushOptions(push *model.Push) http_clients.PushOptions {
+	return http_clients.PushOptions{
+		Protocol:     push.Protocol,
+		URL:          push.DestinationUrl(),
+		StreamKey:    push.StreamKey,
+		VariantNames: push.VariantNames,
+		TrackIds:     push.TrackIds,
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrStreamNotLive          = errors.New("stream is not live")
+	ErrRecordingAlreadyActive = errors.New("stream is already being recorded")
+)
+
+// StartRecording starts a server-side recording of a live stream. Like StartPush, the recording is
+// stored as "pending" first and rolled back to "failed" when OME rejects it.
+func (s OmeService) StartRecording(ctx context.Context, stream *model.Stream, recording *model.Recording) (*model.Recording, error) {
+	if !stream.IsLive() {
+		return nil, ErrStreamNotLive
+	}
+
+	activeRecordings, err := s.FindRecordingsByStreamIdAndStates(ctx, stream.Id, []string{"pending", "ready", "recording"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing recordings: %w", err)
+	}
+	if len(activeRecordings) > 0 {
+		return nil, ErrRecordingAlreadyActive
+	}
+
+	err = s.EnsureServerAvailable(ctx, stream.ServerIpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	recording.Id = primitive.NewObjectID()
+	recording.StreamId = stream.Id
+	recording.ServerIpAddress = stream.ServerIpAddress
+	recording.App = s.GetStreamApp(stream)
+	recording.State = "pending"
+	currentTime := time.Now()
+	recording.CreatedAt = currentTime
+	recording.UpdatedAt = currentTime
+	_, err = s.recordingCollection.InsertOne(ctx, recording)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording record: %w", err)
+	}
+
+	// Recordings use the same output stream as pushes, which carries AAC audio that MP4 files need.
+	record, err := s.omeHttpClient.StartRecord(
+		recording.ServerIpAddress,
+		recording.App,
+		s.GetPushSourceStreamName(s.GetStreamIngestName(stream)),
+		recording.Id.Hex(),
+		http_clients.RecordOptions{
+			FilePath:         recording.FilePath,
+			InfoPath:         recording.InfoPath,
+			Interval:         recording.Interval,
+			Schedule:         recording.Schedule,
+			SegmentationRule: recording.SegmentationRule,
+			VariantNames:     recording.VariantNames,
+		})
+	if err != nil {
+		_, rollbackErr := s.UpdateRecordingByID(ctx, recording.Id, bson.M{
+			"state":          "failed",
+			"failure_reason": err.Error(),
+		})
+		if rollbackErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("failed to roll back recording record: %w", rollbackErr))
+		}
+		return nil, err
+	}
+
+	state := record.State
+	if state == "" {
+		state = "ready"
+	}
+	return s.UpdateRecordingByID(ctx, recording.Id, bson.M{
+		"state":      state,
+		"started_at": time.Now(),
+	})
+}
+
+// StopRecording asks OME to stop a recording. OME finalizes the files asynchronously, so the
+// recording stays "stopping" until SyncRecordings sees it finished.
+func (s OmeService) StopRecording(ctx context.Context, recording *model.Recording) (*model.Recording, error) {
+	if recording.State == "pending" {
+		return s.UpdateRecordingByID(ctx, recording.Id, bson.M{
+			"state":          "failed",
+			"failure_reason": "stopped before it started",
+		})
+	}
+
+	err := s.omeHttpClient.StopRecord(recording.ServerIpAddress, recording.App, recording.Id.Hex())
+	if errors.Is(err, http_clients.ErrRecordNotFound) {
+		return s.UpdateRecordingByID(ctx, recording.Id, bson.M{
+			"state":       "stopped",
+			"finished_at": time.Now(),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.UpdateRecordingByID(ctx, recording.Id, bson.M{
+		"state": "stopping",
+	})
+}
+
+// SyncRecordings copies state, byte counts and output paths from OME's records list into the given
+// recordings. Recordings OME no longer knows of are considered stopped. Recordings on a server
+// whose list can't be fetched are left as they are while the others are still synced.
+func (s OmeService) SyncRecordings(ctx context.Context, recordings []model.Recording) error {
+	type serverApp struct {
+		ip  string
+		app string
+	}
+	recordsByServer := map[serverApp]map[string]http_clients.RecordDetails{}
+	failedServers := map[serverApp]bool{}
+	var syncErrs []error
+
+	for i := range recordings {
+		recording := &recordings[i]
+		key := serverApp{ip: recording.ServerIpAddress, app: recording.App}
+		if failedServers[key] {
+			continue
+		}
+		records, ok := recordsByServer[key]
+		if !ok {
+			omeRecords, err := s.omeHttpClient.ListRecords(key.ip, key.app)
+			if err != nil {
+				syncErrs = append(syncErrs, fmt.Errorf("%s/%s: %w", key.ip, key.app, err))
+				failedServers[key] = true
+				continue
+			}
+			records = map[string]http_clients.RecordDetails{}
+			for _, record := range omeRecords {
+				records[record.ID] = record
+			}
+			recordsByServer[key] = records
+		}
+
+		currentTime := time.Now()
+		update := bson.M{"synced_at": currentTime}
+		record, ok := records[recording.Id.Hex()]
+		if !ok {
+			update["state"] = "stopped"
+			update["finished_at"] = currentTime
+		} else {
+			update["state"] = record.State
+			update["sequence"] = record.Sequence
+			update["total_record_bytes"] = record.TotalRecordBytes
+			update["total_record_time"] = record.TotalRecordTime
+			if record.OutputFilePath != "" {
+				update["output_file_path"] = record.OutputFilePath
+			}
+			if record.OutputInfoPath != "" {
+				update["output_info_path"] = record.OutputInfoPath
+			}
+			if record.State == "stopped" || record.State == "error" {
+				update["finished_at"] = currentTime
+			}
+		}
+
+		_, err := s.UpdateRecordingByID(ctx, recording.Id, update)
+		if err != nil {
+			syncErrs = append(syncErrs, err)
+		}
+	}
+	return errors.Join(syncErrs...)
+}
+
+// SyncStreamRecordings syncs the recordings of a stream that OME may still be writing.
+func (s OmeService) SyncStreamRecordings(ctx context.Context, streamId primitive.ObjectID) error {
+	recordings, err := s.FindRecordingsByStreamIdAndStates(ctx, streamId, []string{"ready", "recording", "stopping"})
+	if err != nil {
+		return err
+	}
+	return s.SyncRecordings(ctx, recordings)
+}
+
+func (s OmeService) FindRecordingsByStreamIdAndStates(ctx context.Context, streamId primitive.ObjectID, states []string) ([]model.Recording, error) {
+	filter := bson.M{"stream_id": streamId, "state": bson.M{"$in": states}}
+	cursor, err := s.recordingCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	recordings := make([]model.Recording, 0)
+	if err := cursor.All(ctx, &recordings); err != nil {
+		return nil, err
+	}
+	return recordings, nil
+}
+
+func (s OmeService) ListRecordingsByStreamId(ctx context.Context, streamId primitive.ObjectID) ([]model.Recording, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := s.recordingCollection.Find(ctx, bson.M{"stream_id": streamId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	recordings := make([]model.Recording, 0)
+	if err := cursor.All(ctx, &recordings); err != nil {
+		return nil, err
+	}
+	return recordings, nil
+}
+
+func (s OmeService) FindRecordingById(ctx context.Context, id primitive.ObjectID) (*model.Recording, error) {
+	var result model.Recording
+
+	err := s.recordingCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s OmeService) UpdateRecordingByID(ctx context.Context, id primitive.ObjectID, update bson.M) (*model.Recording, error) {
+	update["updated_at"] = time.Now()
+
+	result, err := s.recordingCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": update})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+	return s.FindRecordingById(ctx, id)
+}
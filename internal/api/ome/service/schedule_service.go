@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// scheduleClaimTimeout is how long a schedule may stay "starting" before it is considered
+	// abandoned, e.g. because the instance starting it crashed.
+	scheduleClaimTimeout = time.Minute
+	// scheduleRetryDelay spaces out the scheduler's attempts to start a schedule whose pushes all failed.
+	scheduleRetryDelay = 30 * time.Second
+)
+
+func (s OmeService) CreateSchedule(ctx context.Context, model *model.Schedule) (*model.Schedule, error) {
+	model.Id = primitive.NewObjectID()
+	model.Status = "scheduled"
+	model.PushIds = []primitive.ObjectID{}
+	currentTime := time.Now()
+	model.CreatedAt = currentTime
+	model.UpdatedAt = currentTime
+
+	_, err := s.scheduleCollection.InsertOne(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+func (s OmeService) ListSchedulesByStreamId(ctx context.Context, streamId primitive.ObjectID) ([]model.Schedule, error) {
+	opts := options.Find().SetSort(bson.M{"start_at": 1})
+	cursor, err := s.scheduleCollection.Find(ctx, bson.M{"stream_id": streamId, "deleted_at": nil}, opts)
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]model.Schedule, 0)
+	if err := cursor.All(ctx, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (s OmeService) FindScheduleById(ctx context.Context, id primitive.ObjectID) (*model.Schedule, error) {
+	var result model.Schedule
+
+	err := s.scheduleCollection.FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s OmeService) UpdateScheduleByID(ctx context.Context, id primitive.ObjectID, update bson.M) (*model.Schedule, error) {
+	update["updated_at"] = time.Now()
+
+	result, err := s.scheduleCollection.UpdateOne(ctx, bson.M{"_id": id, "deleted_at": nil}, bson.M{"$set": update})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+	return s.FindScheduleById(ctx, id)
+}
+
+// DeleteSchedule stops the pushes of a running schedule and soft-deletes it.
+func (s OmeService) DeleteSchedule(ctx context.Context, schedule *model.Schedule) error {
+	if schedule.Status == "running" {
+		_, err := s.EndSchedule(ctx, schedule, "cancelled")
+		if err != nil {
+			return err
+		}
+	}
+
+	currentTime := time.Now()
+	_, err := s.scheduleCollection.UpdateOne(ctx, bson.M{"_id": schedule.Id, "deleted_at": nil}, bson.M{
+		"$set": bson.M{"deleted_at": currentTime, "updated_at": currentTime},
+	})
+	return err
+}
+
+// FindSchedulesDueToStart returns schedules whose window is open but which haven't started yet,
+// e.g. because the publisher isn't live, along with abandoned starts. Schedules whose last attempt
+// started no push are retried after scheduleRetryDelay.
+func (s OmeService) FindSchedulesDueToStart(ctx context.Context, now time.Time) ([]model.Schedule, error) {
+	return s.findSchedules(ctx, bson.M{
+		"$or": []bson.M{
+			{"status": "scheduled", "last_attempt_at": bson.M{"$not": bson.M{"$gt": now.Add(-scheduleRetryDelay)}}},
+			{"status": "starting", "updated_at": bson.M{"$lt": now.Add(-scheduleClaimTimeout)}},
+		},
+		"start_at":   bson.M{"$lte": now},
+		"end_at":     bson.M{"$gt": now},
+		"deleted_at": nil,
+	})
+}
+
+// FindSchedulesDueToEnd returns schedules whose window has closed without them being ended.
+// Schedules being started are left to finish starting unless the start was abandoned.
+func (s OmeService) FindSchedulesDueToEnd(ctx context.Context, now time.Time) ([]model.Schedule, error) {
+	return s.findSchedules(ctx, bson.M{
+		"$or": []bson.M{
+			{"status": bson.M{"$in": []string{"scheduled", "running"}}},
+			{"status": "starting", "updated_at": bson.M{"$lt": now.Add(-scheduleClaimTimeout)}},
+		},
+		"end_at":     bson.M{"$lte": now},
+		"deleted_at": nil,
+	})
+}
+
+// FindOpenSchedulesByStreamId returns the schedules of a stream whose window is open.
+func (s OmeService) FindOpenSchedulesByStreamId(ctx context.Context, streamId primitive.ObjectID, now time.Time) ([]model.Schedule, error) {
+	return s.findSchedules(ctx, bson.M{
+		"stream_id":  streamId,
+		"status":     bson.M{"$in": []string{"scheduled", "running"}},
+		"start_at":   bson.M{"$lte": now},
+		"end_at":     bson.M{"$gt": now},
+		"deleted_at": nil,
+	})
+}
+
+func (s OmeService) findSchedules(ctx context.Context, filter bson.M) ([]model.Schedule, error) {
+	cursor, err := s.scheduleCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]model.Schedule, 0)
+	if err := cursor.All(ctx, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// StartSchedule starts a push for every destination of the schedule that isn't already being
+// pushed to, so it is also used to restore the pushes when the publisher reconnects. A destination
+// that fails to start is recorded on the schedule without failing the others. The schedule is
+// claimed first so the scheduler and the publish webhook can't both start it; a schedule that is
+// claimed elsewhere is skipped and nil is returned.
+func (s OmeService) StartSchedule(ctx context.Context, stream *model.Stream, schedule *model.Schedule) (*model.Schedule, error) {
+	if !stream.IsLive() {
+		return nil, ErrStreamNotLive
+	}
+
+	claimed, err := s.claimSchedule(ctx, schedule)
+	if err != nil || !claimed {
+		return nil, err
+	}
+
+	pushIds := make([]primitive.ObjectID, 0, len(schedule.Destinations))
+	var startErrs []error
+	for _, destination := range schedule.Destinations {
+		// Only pushes the schedule started itself are reused, so ending it never stops a push
+		// someone started by hand.
+		existingPush, err := s.findSchedulePush(ctx, schedule, destination.Url)
+		if err != nil {
+			startErrs = append(startErrs, fmt.Errorf("%s: %w", destination.Url, err))
+			continue
+		}
+		if existingPush != nil {
+			pushIds = append(pushIds, existingPush.Id)
+			continue
+		}
+
+		push, err := s.StartPush(ctx, stream, scheduleDestinationPush(destination))
+		if err != nil {
+			startErrs = append(startErrs, fmt.Errorf("%s: %w", destination.Url, err))
+			continue
+		}
+		pushIds = append(pushIds, push.Id)
+	}
+
+	currentTime := time.Now()
+	update := bson.M{
+		"status":          "running",
+		"push_ids":        pushIds,
+		"last_error":      "",
+		"last_attempt_at": currentTime,
+	}
+	if len(startErrs) > 0 {
+		update["last_error"] = errors.Join(startErrs...).Error()
+	}
+	if len(pushIds) == 0 && len(schedule.Destinations) > 0 {
+		// Nothing is being pushed, so leave the schedule for the scheduler to retry.
+		update["status"] = "scheduled"
+	} else if schedule.StartedAt == nil {
+		update["started_at"] = currentTime
+	}
+	update["updated_at"] = currentTime
+
+	result, err := s.scheduleCollection.UpdateOne(ctx, bson.M{
+		"_id":        schedule.Id,
+		"status":     "starting",
+		"deleted_at": nil,
+	}, bson.M{"$set": update})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		// The schedule was deleted while it was starting, so nothing will end the pushes later.
+		return nil, s.stopSchedulePushes(ctx, stream, pushIds)
+	}
+	return s.FindScheduleById(ctx, schedule.Id)
+}
+
+// claimSchedule moves a schedule to "starting" unless it changed since it was read, which means
+// another caller is starting or ending it.
+func (s OmeService) claimSchedule(ctx context.Context, schedule *model.Schedule) (bool, error) {
+	result, err := s.scheduleCollection.UpdateOne(ctx, bson.M{
+		"_id":        schedule.Id,
+		"status":     schedule.Status,
+		"updated_at": schedule.UpdatedAt,
+		"deleted_at": nil,
+	}, bson.M{
+		"$set": bson.M{"status": "starting", "updated_at": time.Now()},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// findSchedulePush returns the push the schedule started to destinationUrl if it is still running.
+func (s OmeService) findSchedulePush(ctx context.Context, schedule *model.Schedule, destinationUrl string) (*model.Push, error) {
+	for _, pushId := range schedule.PushIds {
+		push, err := s.FindPushById(ctx, pushId)
+		if err != nil {
+			return nil, err
+		}
+		if push == nil || push.Url != destinationUrl {
+			continue
+		}
+		if push.Status == "active" || push.Status == "pending" || push.Status == "retrying" {
+			return push, nil
+		}
+	}
+	return nil, nil
+}
+
+// EndSchedule stops the pushes a schedule started and moves it to the given final status.
+// Schedules that never started are marked "missed" instead of "completed".
+func (s OmeService) EndSchedule(ctx context.Context, schedule *model.Schedule, status string) (*model.Schedule, error) {
+	if status == "completed" && schedule.StartedAt == nil {
+		status = "missed"
+	}
+
+	stream, err := s.FindStreamById(ctx, schedule.StreamId)
+	if err != nil {
+		return nil, err
+	}
+	if stream != nil {
+		err = s.stopSchedulePushes(ctx, stream, schedule.PushIds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.UpdateScheduleByID(ctx, schedule.Id, bson.M{
+		"status":   status,
+		"ended_at": time.Now(),
+	})
+}
+
+func (s OmeService) stopSchedulePushes(ctx context.Context, stream *model.Stream, pushIds []primitive.ObjectID) error {
+	for _, pushId := range pushIds {
+		push, err := s.FindPushById(ctx, pushId)
+		if err != nil {
+			return err
+		}
+		if push == nil || (push.Status != "active" && push.Status != "pending" && push.Status != "retrying") {
+			continue
+		}
+		err = s.StopPush(ctx, stream, push)
+		if err != nil {
+			return fmt.Errorf("failed to stop scheduled push: %w", err)
+		}
+	}
+	return nil
+}
+
+// StartOpenSchedules starts the schedules of a stream whose window is open. It is called when the
+// publisher goes live so late publishers don't wait for the next scheduler tick.
+func (s OmeService) StartOpenSchedules(ctx context.Context, stream *model.Stream) error {
+	schedules, err := s.FindOpenSchedulesByStreamId(ctx, stream.Id, time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range schedules {
+		_, err := s.StartSchedule(ctx, stream, &schedules[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scheduleDestinationPush(destination model.ScheduleDestination) *model.Push {
+	push := &model.Push{
+		Protocol:     destination.Protocol,
+		Url:          destination.Url,
+		StreamKey:    destination.StreamKey,
+		VariantNames: destination.VariantNames,
+		TrackIds:     destination.TrackIds,
+		RetryPolicy:  destination.RetryPolicy,
+	}
+	if push.Protocol == "rtmp" {
+		push.RtmpUrl = push.Url
+	}
+	return push
+}
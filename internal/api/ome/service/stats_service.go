@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+)
+
+const defaultVhost = "default"
+
+// StreamStats is the normalized live statistics of a stream. Bitrates are in bits per second.
+type StreamStats struct {
+	StreamId         string         `json:"stream_id"`
+	Live             bool           `json:"live"`
+	Viewers          int            `json:"viewers"`
+	MaxViewers       int            `json:"max_viewers"`
+	Connections      map[string]int `json:"connections"`
+	IngestBitrate    int64          `json:"ingest_bitrate"`
+	EgressBitrate    int64          `json:"egress_bitrate"`
+	AvgIngestBitrate int64          `json:"avg_ingest_bitrate"`
+	AvgEgressBitrate int64          `json:"avg_egress_bitrate"`
+	TotalBytesIn     int64          `json:"total_bytes_in"`
+	TotalBytesOut    int64          `json:"total_bytes_out"`
+	FetchedAt        time.Time      `json:"fetched_at"`
+}
+
+type statsCacheEntry struct {
+	stats     *StreamStats
+	expiresAt time.Time
+}
+
+// statsCache keeps stream stats for STATS_CACHE_TTL so dashboards polling many times a second
+// don't each hit OME.
+type statsCache struct {
+	mu      sync.Mutex
+	entries map[string]statsCacheEntry
+}
+
+func newStatsCache() *statsCache {
+	return &statsCache{entries: map[string]statsCacheEntry{}}
+}
+
+func (c *statsCache) get(key string, now time.Time) (*StreamStats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || now.After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.stats, true
+}
+
+// set stores stats under key and sweeps expired entries, so streams that are no longer polled
+// don't stay in the cache.
+func (c *statsCache) set(key string, stats *StreamStats, now time.Time, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for existingKey, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, existingKey)
+		}
+	}
+	c.entries[key] = statsCacheEntry{stats: stats, expiresAt: expiresAt}
+}
+
+// GetStreamStats returns the live statistics of a stream from the OME server it is published on.
+// Streams that aren't live on OME are reported with Live set to false.
+func (s OmeService) GetStreamStats(stream *model.Stream) (*StreamStats, error) {
+	now := time.Now()
+	cacheKey := stream.Id.Hex()
+	if stats, ok := s.statsCache.get(cacheKey, now); ok {
+		return stats, nil
+	}
+
+	stats, err := s.FetchStreamStats(stream, now)
+	if err != nil {
+		return nil, err
+	}
+
+	s.statsCache.set(cacheKey, stats, now, now.Add(config.AppConfig.STATS_CACHE_TTL))
+	return stats, nil
+}
+
+// FetchStreamStats reads the live statistics of a stream from OME, bypassing the cache, and
+// stamps them with fetchedAt.
+func (s OmeService) FetchStreamStats(stream *model.Stream, fetchedAt time.Time) (*StreamStats, error) {
+	stats := &StreamStats{
+		StreamId:    stream.Id.Hex(),
+		Connections: map[string]int{},
+		FetchedAt:   fetchedAt,
+	}
+	if stream.ServerIpAddress != "" {
+		omeStats, err := s.omeHttpClient.GetStreamStats(stream.ServerIpAddress, defaultVhost, s.GetStreamApp(stream), stream.Id.Hex())
+		if err != nil && !errors.Is(err, http_clients.ErrStreamNotFound) {
+			return nil, err
+		}
+		if omeStats != nil {
+			stats.Live = true
+			stats.Viewers = omeStats.TotalConnections
+			stats.MaxViewers = omeStats.MaxTotalConnections
+			stats.IngestBitrate = omeStats.LastThroughputIn
+			stats.EgressBitrate = omeStats.LastThroughputOut
+			stats.AvgIngestBitrate = omeStats.AvgThroughputIn
+			stats.AvgEgressBitrate = omeStats.AvgThroughputOut
+			stats.TotalBytesIn = omeStats.TotalBytesIn
+			stats.TotalBytesOut = omeStats.TotalBytesOut
+			if omeStats.Connections != nil {
+				stats.Connections = omeStats.Connections
+			}
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type StreamFilter struct {
+	Status          string
+	Protocol        string
+	ServerIpAddress string
+	ExternalId      string
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	SortBy          string
+	SortOrder       int
+	Page            int64
+	Limit           int64
+}
+
+func (s OmeService) ListStreams(ctx context.Context, streamFilter StreamFilter) ([]model.Stream, int64, error) {
+	filter := bson.M{"deleted_at": nil}
+	if streamFilter.Status != "" {
+		filter["status"] = streamFilter.Status
+	}
+	if streamFilter.Protocol != "" {
+		filter["protocol"] = streamFilter.Protocol
+	}
+	if streamFilter.ServerIpAddress != "" {
+		filter["server_ip_address"] = streamFilter.ServerIpAddress
+	}
+	if streamFilter.ExternalId != "" {
+		filter["external_id"] = streamFilter.ExternalId
+	}
+	if streamFilter.CreatedFrom != nil || streamFilter.CreatedTo != nil {
+		createdAt := bson.M{}
+		if streamFilter.CreatedFrom != nil {
+			createdAt["$gte"] = *streamFilter.CreatedFrom
+		}
+		if streamFilter.CreatedTo != nil {
+			createdAt["$lte"] = *streamFilter.CreatedTo
+		}
+		filter["created_at"] = createdAt
+	}
+
+	total, err := s.streamCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: streamFilter.SortBy, Value: streamFilter.SortOrder}, {Key: "_id", Value: streamFilter.SortOrder}}).
+		SetSkip((streamFilter.Page - 1) * streamFilter.Limit).
+		SetLimit(streamFilter.Limit)
+	cursor, err := s.streamCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	streams := make([]model.Stream, 0)
+	if err := cursor.All(ctx, &streams); err != nil {
+		return nil, 0, err
+	}
+	return streams, total, nil
+}
+
+// ListStreamServerIps returns every OME server IP that a stream has been published to.
+func (s OmeService) ListStreamServerIps(ctx context.Context) ([]string, error) {
+	values, err := s.streamCollection.Distinct(ctx, "server_ip_address", bson.M{
+		"server_ip_address": bson.M{"$nin": []interface{}{"", nil}},
+		"deleted_at":        nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]string, 0, len(values))
+	for _, value := range values {
+		if ip, ok := value.(string); ok {
+			ips = append(ips, ip)
+		}
+	}
+	return ips, nil
+}
+
+func (s OmeService) FindStreamsByStatus(ctx context.Context, status string) ([]model.Stream, error) {
+	cursor, err := s.streamCollection.Find(ctx, bson.M{"status": status, "deleted_at": nil})
+	if err != nil {
+		return nil, err
+	}
+
+	var streams []model.Stream
+	err = cursor.All(ctx, &streams)
+	if err != nil {
+		return nil, err
+	}
+	return streams, nil
+}
+
+// EnsureIndexes creates the indexes the service queries rely on. It is safe to call on every startup.
+func (s OmeService) EnsureIndexes(ctx context.Context) error {
+	indexes := map[*mongo.Collection][]mongo.IndexModel{
+		s.streamCollection: {
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "protocol", Value: 1}}},
+			{Keys: bson.D{{Key: "server_ip_address", Value: 1}}},
+			{Keys: bson.D{{Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+			{Keys: bson.D{{Key: "node_id", Value: 1}, {Key: "status", Value: 1}}},
+			{
+				Keys:    bson.D{{Key: "backup_ingest_name", Value: 1}},
+				Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"backup_ingest_name": bson.M{"$type": "string"}}),
+			},
+		},
+		s.nodeCollection: {
+			{Keys: bson.D{{Key: "ip_address", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		s.pushCollection: {
+			{Keys: bson.D{{Key: "stream_id", Value: 1}, {Key: "status", Value: 1}}},
+			{Keys: bson.D{{Key: "server_ip_address", Value: 1}, {Key: "status", Value: 1}}},
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_retry_at", Value: 1}}},
+		},
+		s.recordingCollection: {
+			{Keys: bson.D{{Key: "stream_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "stream_id", Value: 1}, {Key: "state", Value: 1}}},
+			{Keys: bson.D{{Key: "state", Value: 1}, {Key: "cataloged_at", Value: 1}}},
+		},
+		s.scheduleCollection: {
+			{Keys: bson.D{{Key: "stream_id", Value: 1}, {Key: "start_at", Value: 1}}},
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "start_at", Value: 1}}},
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "end_at", Value: 1}}},
+		},
+		s.channelCollection: {
+			{Keys: bson.D{{Key: "deleted_at", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		s.vodAssetCollection: {
+			{Keys: bson.D{{Key: "recording_id", Value: 1}, {Key: "source_path", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "stream_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		s.streamSessionCollection: {
+			{Keys: bson.D{{Key: "stream_id", Value: 1}, {Key: "started_at", Value: -1}}},
+		},
+		s.idempotencyCollection: {
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		s.viewerSessionCollection: {
+			{Keys: bson.D{{Key: "stream_id", Value: 1}, {Key: "client_address", Value: 1}, {Key: "client_port", Value: 1}, {Key: "status", Value: 1}}},
+		},
+	}
+
+	for collection, models := range indexes {
+		_, err := collection.Indexes().CreateMany(ctx, models)
+		if err != nil {
+			return err
+		}
+	}
+	return s.ensureExternalIdIndex(ctx)
+}
+
+// ensureExternalIdIndex makes external_id unique among live streams and drops the non-unique index
+// it replaces. deleted_at is null for every live stream, so soft-deleted streams keep their
+// external_id. Databases that already hold duplicate live external_ids can't get the index; they
+// are logged for cleanup and startup continues without it.
+func (s OmeService) ensureExternalIdIndex(ctx context.Context) error {
+	_, err := s.streamCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "external_id", Value: 1}, {Key: "deleted_at", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		duplicates, findErr := s.findDuplicateExternalIds(ctx)
+		if findErr != nil {
+			return findErr
+		}
+		fmt.Println("Warning: external_id isn't unique, resolve the duplicate live streams and restart:", duplicates)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.streamCollection.Indexes().DropOne(ctx, "external_id_1")
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Name == "IndexNotFound" {
+		return nil
+	}
+	return err
+}
+
+// findDuplicateExternalIds returns the external_ids shared by more than one live stream.
+func (s OmeService) findDuplicateExternalIds(ctx context.Context) ([]string, error) {
+	cursor, err := s.streamCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"deleted_at": nil}}},
+		{{Key: "$group", Value: bson.M{"_id": "$external_id", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		ExternalId string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	externalIds := make([]string, 0, len(results))
+	for _, result := range results {
+		externalIds = append(externalIds, result.ExternalId)
+	}
+	return externalIds, nil
+}
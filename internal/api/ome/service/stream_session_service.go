@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OpenStreamSession closes any session left open for the stream (e.g. after a takeover)
+// and starts a new one.
+func (s OmeService) OpenStreamSession(ctx context.Context, model *model.StreamSession) (*model.StreamSession, error) {
+	err := s.CloseStreamSession(ctx, model.StreamId)
+	if err != nil {
+		return nil, err
+	}
+
+	model.Id = primitive.NewObjectID()
+	currentTime := time.Now()
+	model.Status = "opening"
+	model.StartedAt = currentTime
+	model.CreatedAt = currentTime
+	model.UpdatedAt = currentTime
+
+	_, err = s.streamSessionCollection.InsertOne(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// CloseStreamSession closes the open sessions of a stream and records their duration.
+func (s OmeService) CloseStreamSession(ctx context.Context, streamId primitive.ObjectID) error {
+	cursor, err := s.streamSessionCollection.Find(ctx, bson.M{"stream_id": streamId, "status": "opening"})
+	if err != nil {
+		return err
+	}
+	var openSessions []model.StreamSession
+	if err := cursor.All(ctx, &openSessions); err != nil {
+		return err
+	}
+
+	currentTime := time.Now()
+	for _, session := range openSessions {
+		_, err := s.streamSessionCollection.UpdateByID(ctx, session.Id, bson.M{"$set": bson.M{
+			"status":      "closing",
+			"ended_at":    currentTime,
+			"duration_ms": currentTime.Sub(session.StartedAt).Milliseconds(),
+			"updated_at":  currentTime,
+		}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s OmeService) FindOpenStreamSession(ctx context.Context, streamId primitive.ObjectID) (*model.StreamSession, error) {
+	var result model.StreamSession
+
+	filter := bson.M{"stream_id": streamId, "status": "opening"}
+	opts := options.FindOne().SetSort(bson.M{"started_at": -1})
+	err := s.streamSessionCollection.FindOne(ctx, filter, opts).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s OmeService) ListStreamSessions(ctx context.Context, streamId primitive.ObjectID) ([]model.StreamSession, error) {
+	opts := options.Find().SetSort(bson.M{"started_at": -1})
+	cursor, err := s.streamSessionCollection.Find(ctx, bson.M{"stream_id": streamId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]model.StreamSession, 0)
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
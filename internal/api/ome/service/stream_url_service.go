@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+)
+
+const (
+	defaultRtmpPort        = 1935
+	defaultSrtIngestPort   = 9999
+	defaultSrtPlaybackPort = 9998
+)
+
+// StreamUrls are the endpoints encoders publish a stream to and players play it from.
+type StreamUrls struct {
+	Ingest       IngestUrls   `json:"ingest"`
+	BackupIngest *IngestUrls  `json:"backup_ingest,omitempty"`
+	Playback     PlaybackUrls `json:"playback"`
+}
+
+type IngestUrls struct {
+	Whip          string `json:"whip"`
+	Rtmp          string `json:"rtmp"`
+	RtmpServer    string `json:"rtmp_server"`
+	RtmpStreamKey string `json:"rtmp_stream_key"`
+	Srt           string `json:"srt"`
+}
+
+type PlaybackUrls struct {
+	WebRtc string `json:"webrtc"`
+	LlHls  string `json:"llhls"`
+	Hls    string `json:"hls"`
+	Dash   string `json:"dash"`
+	Srt    string `json:"srt"`
+}
+
+// GetStreamUrls builds the ingest and playback URLs of a stream from the endpoint configuration
+// of its node, or from OME_SERVER_BASE_URL when it isn't placed on a registered node.
+func (s OmeService) GetStreamUrls(ctx context.Context, stream *model.Stream) (*StreamUrls, error) {
+	endpoints, err := s.getStreamEndpoints(ctx, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	app := s.GetStreamApp(stream)
+	urls := &StreamUrls{
+		Ingest:   buildIngestUrls(endpoints, app, stream.Id.Hex()),
+		Playback: buildPlaybackUrls(endpoints, app, stream.Id.Hex()),
+	}
+	if stream.BackupIngestName != "" {
+		backupIngest := buildIngestUrls(endpoints, app, stream.BackupIngestName)
+		urls.BackupIngest = &backupIngest
+	}
+	return urls, nil
+}
+
+// getStreamEndpoints resolves the endpoint configuration of the node a stream is placed on,
+// filling unset fields, including TLS, from the node's ingest URL.
+func (s OmeService) getStreamEndpoints(ctx context.Context, stream *model.Stream) (model.NodeEndpoints, error) {
+	baseUrl := config.AppConfig.OME_SERVER_BASE_URL
+	endpoints := model.NodeEndpoints{}
+	if !stream.NodeId.IsZero() {
+		node, err := s.FindNodeById(ctx, stream.NodeId)
+		if err != nil {
+			return endpoints, err
+		}
+		if node != nil {
+			baseUrl = node.IngestUrl
+			endpoints = node.Endpoints
+		}
+	}
+
+	parsedUrl, err := url.Parse(baseUrl)
+	if err != nil {
+		return endpoints, fmt.Errorf("invalid ingest url %q: %w", baseUrl, err)
+	}
+	if endpoints.Tls == nil {
+		tls := parsedUrl.Scheme == "https"
+		endpoints.Tls = &tls
+	}
+	if endpoints.Hostname == "" {
+		endpoints.Hostname = parsedUrl.Hostname()
+	}
+	if endpoints.BasePath == "" {
+		endpoints.BasePath = parsedUrl.Path
+	}
+	endpoints.BasePath = strings.TrimSuffix(endpoints.BasePath, "/")
+	// Without an explicit port the ingest URL is served on the scheme's default port, e.g. behind
+	// a proxy, and so are WebRTC and HTTP playback.
+	basePort, _ := strconv.Atoi(parsedUrl.Port())
+	if endpoints.WebRtcPort == 0 {
+		endpoints.WebRtcPort = basePort
+	}
+	if endpoints.HttpPort == 0 {
+		endpoints.HttpPort = endpoints.WebRtcPort
+	}
+	if endpoints.RtmpPort == 0 {
+		endpoints.RtmpPort = defaultRtmpPort
+	}
+	if endpoints.SrtIngestPort == 0 {
+		endpoints.SrtIngestPort = defaultSrtIngestPort
+	}
+	if endpoints.SrtPlaybackPort == 0 {
+		endpoints.SrtPlaybackPort = defaultSrtPlaybackPort
+	}
+	return endpoints, nil
+}
+
+func buildIngestUrls(endpoints model.NodeEndpoints, app string, streamName string) IngestUrls {
+	rtmpServer := "rtmp://" + hostPort(endpoints.Hostname, endpoints.RtmpPort) + "/" + app
+	return IngestUrls{
+		Whip:          httpScheme(endpoints) + "://" + hostPort(endpoints.Hostname, endpoints.WebRtcPort) + endpoints.BasePath + "/" + app + "/" + streamName + "?direction=whip",
+		Rtmp:          rtmpServer + "/" + streamName,
+		RtmpServer:    rtmpServer,
+		RtmpStreamKey: streamName,
+		Srt:           srtUrl(endpoints.Hostname, endpoints.SrtIngestPort, app, streamName),
+	}
+}
+
+func buildPlaybackUrls(endpoints model.NodeEndpoints, app string, streamName string) PlaybackUrls {
+	webRtcScheme := "ws"
+	if usesTls(endpoints) {
+		webRtcScheme = "wss"
+	}
+	httpBase := httpScheme(endpoints) + "://" + hostPort(endpoints.Hostname, endpoints.HttpPort) + endpoints.BasePath + "/" + app + "/" + streamName
+	return PlaybackUrls{
+		WebRtc: webRtcScheme + "://" + hostPort(endpoints.Hostname, endpoints.WebRtcPort) + endpoints.BasePath + "/" + app + "/" + streamName,
+		LlHls:  httpBase + "/llhls.m3u8",
+		Hls:    httpBase + "/ts:playlist.m3u8",
+		Dash:   httpBase + "/manifest.mpd",
+		Srt:    srtUrl(endpoints.Hostname, endpoints.SrtPlaybackPort, app, streamName),
+	}
+}
+
+func httpScheme(endpoints model.NodeEndpoints) string {
+	if usesTls(endpoints) {
+		return "https"
+	}
+	return "http"
+}
+
+func usesTls(endpoints model.NodeEndpoints) bool {
+	return endpoints.Tls != nil && *endpoints.Tls
+}
+
+// hostPort joins host and port, leaving the port out when it is 0. IPv6 hosts are bracketed.
+func hostPort(host string, port int) string {
+	if port == 0 {
+		if strings.Contains(host, ":") {
+			return "[" + host + "]"
+		}
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// srtUrl returns an SRT URL in OME's format, where the stream is selected by a percent-encoded
+// streamid holding the full URL.
+func srtUrl(host string, port int, app string, streamName string) string {
+	address := hostPort(host, port)
+	return "srt://" + address + "?streamid=" + url.QueryEscape("srt://"+address+"/"+app+"/"+streamName)
+}
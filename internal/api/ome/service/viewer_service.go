@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/url"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CheckPlaybackAdmission decides whether a viewer may play the given stream based on
+// the stream's viewer policy. It never modifies the publisher state of the stream.
+func (s OmeService) CheckPlaybackAdmission(ctx context.Context, streamName string, requestUrl string) (*model.Stream, model.OmeWebhookResponse, error) {
+	objId, err := primitive.ObjectIDFromHex(streamName)
+	if err != nil {
+		return nil, model.OmeWebhookResponse{Allowed: false, Reason: "unknown stream"}, nil
+	}
+
+	stream, err := s.FindStreamById(ctx, objId)
+	if err != nil {
+		return nil, model.OmeWebhookResponse{Allowed: false, Reason: "internal error"}, err
+	}
+	if stream == nil {
+		return nil, model.OmeWebhookResponse{Allowed: false, Reason: "unknown stream"}, nil
+	}
+
+	switch stream.ViewerPolicy {
+	case "disabled":
+		return stream, model.OmeWebhookResponse{Allowed: false, Reason: "playback is disabled"}, nil
+	case "token":
+		if !s.hasValidViewerToken(requestUrl, stream.ViewerToken) {
+			return stream, model.OmeWebhookResponse{Allowed: false, Reason: "invalid playback token"}, nil
+		}
+	}
+
+	return stream, model.OmeWebhookResponse{Allowed: true}, nil
+}
+
+func (s OmeService) hasValidViewerToken(requestUrl string, expected string) bool {
+	if expected == "" {
+		return false
+	}
+	parsedUrl, err := url.Parse(requestUrl)
+	if err != nil {
+		return false
+	}
+	token := parsedUrl.Query().Get("token")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+func (s OmeService) CreateViewerSession(ctx context.Context, model *model.ViewerSession) (*model.ViewerSession, error) {
+	model.Id = primitive.NewObjectID()
+	currentTime := time.Now()
+	model.Status = "opening"
+	model.StartedAt = currentTime
+	model.CreatedAt = currentTime
+	model.UpdatedAt = currentTime
+
+	_, err := s.viewerSessionCollection.InsertOne(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// CloseViewerSession closes the open viewer session of the given client connection.
+func (s OmeService) CloseViewerSession(ctx context.Context, streamId primitive.ObjectID, clientAddress string, clientPort int) error {
+	currentTime := time.Now()
+	_, err := s.viewerSessionCollection.UpdateOne(
+		ctx,
+		bson.M{
+			"stream_id":      streamId,
+			"client_address": clientAddress,
+			"client_port":    clientPort,
+			"status":         "opening",
+		},
+		bson.M{"$set": bson.M{
+			"status":     "closing",
+			"ended_at":   currentTime,
+			"updated_at": currentTime,
+		}},
+	)
+	return err
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const catalogBatchSize = 20
+
+// RecordingFile is a file OME wrote for a recording. Recordings split by interval or schedule
+// produce one file per segment.
+type RecordingFile struct {
+	Path       string `xml:"filePath"`
+	Sequence   int    `xml:"sequence"`
+	DurationMs int64  `xml:"recordTime"`
+}
+
+// recordingInfo is the XML info file OME writes next to the recorded files.
+type recordingInfo struct {
+	Files []RecordingFile `xml:"file"`
+}
+
+// SyncActiveRecordings syncs every recording OME may still be writing with OME's records list.
+func (s OmeService) SyncActiveRecordings(ctx context.Context) error {
+	cursor, err := s.recordingCollection.Find(ctx, bson.M{"state": bson.M{"$in": []string{"ready", "recording", "stopping"}}})
+	if err != nil {
+		return err
+	}
+	recordings := make([]model.Recording, 0)
+	if err := cursor.All(ctx, &recordings); err != nil {
+		return err
+	}
+	return s.SyncRecordings(ctx, recordings)
+}
+
+// FindRecordingsToCatalog returns stopped recordings whose files haven't been moved into VOD storage
+// yet. Recordings whose catalog failed are left out until an operator resets catalog_failed_at.
+func (s OmeService) FindRecordingsToCatalog(ctx context.Context) ([]model.Recording, error) {
+	opts := options.Find().SetSort(bson.M{"finished_at": 1}).SetLimit(catalogBatchSize)
+	cursor, err := s.recordingCollection.Find(ctx, bson.M{"state": "stopped", "cataloged_at": nil, "catalog_failed_at": nil}, opts)
+	if err != nil {
+		return nil, err
+	}
+	recordings := make([]model.Recording, 0)
+	if err := cursor.All(ctx, &recordings); err != nil {
+		return nil, err
+	}
+	return recordings, nil
+}
+
+// GetRecordingFiles lists the files of a finished recording from its info file, falling back to
+// the last output file OME reported.
+func (s OmeService) GetRecordingFiles(recording *model.Recording) ([]RecordingFile, error) {
+	if recording.OutputInfoPath != "" {
+		content, err := os.ReadFile(recording.OutputInfoPath)
+		if err == nil {
+			var info recordingInfo
+			err = xml.Unmarshal(content, &info)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse recording info file: %w", err)
+			}
+			files := make([]RecordingFile, 0, len(info.Files))
+			for _, file := range info.Files {
+				file.Path = strings.TrimSpace(file.Path)
+				files = append(files, file)
+			}
+			return files, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	if recording.OutputFilePath == "" {
+		return []RecordingFile{}, nil
+	}
+	return []RecordingFile{{
+		Path:       recording.OutputFilePath,
+		Sequence:   recording.Sequence,
+		DurationMs: recording.TotalRecordTime,
+	}}, nil
+}
+
+// CatalogRecording moves the files of a finished recording into store and records them as VOD
+// assets. Each asset is inserted as "pending" before its file is moved, so a run interrupted in
+// between finds the moved file in store on the next run instead of losing it.
+//
+// Files missing from this host, e.g. because the recording was made on a remote OME node, mark
+// the recording's catalog as failed instead of cataloging it without them.
+func (s OmeService) CatalogRecording(ctx context.Context, recording *model.Recording, store storage.Storage) error {
+	files, err := s.GetRecordingFiles(recording)
+	if err != nil {
+		return err
+	}
+
+	var missingFiles []string
+	for _, file := range files {
+		asset, err := s.findVodAssetBySource(ctx, recording.Id, file.Path)
+		if err != nil {
+			return err
+		}
+		if asset != nil && asset.Status != "pending" {
+			continue
+		}
+
+		if asset == nil {
+			asset, err = s.createPendingVodAsset(ctx, recording, file, store)
+			if err != nil {
+				return err
+			}
+			if asset == nil {
+				missingFiles = append(missingFiles, file.Path)
+				continue
+			}
+		}
+
+		location, err := storeVodAsset(ctx, asset, store)
+		if err != nil {
+			return err
+		}
+		if location == "" {
+			missingFiles = append(missingFiles, file.Path)
+			continue
+		}
+		_, err = s.vodAssetCollection.UpdateByID(ctx, asset.Id, bson.M{"$set": bson.M{
+			"status":     "ready",
+			"file_path":  location,
+			"updated_at": time.Now(),
+		}})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(missingFiles) > 0 {
+		fmt.Printf("Recording %s has files missing on this host: %v\n", recording.Id.Hex(), missingFiles)
+		_, err = s.UpdateRecordingByID(ctx, recording.Id, bson.M{
+			"catalog_failed_at": time.Now(),
+			"catalog_failure":   fmt.Sprintf("recording files not found on this host: %s", strings.Join(missingFiles, ", ")),
+		})
+		return err
+	}
+
+	_, err = s.UpdateRecordingByID(ctx, recording.Id, bson.M{
+		"cataloged_at": time.Now(),
+	})
+	return err
+}
+
+// createPendingVodAsset records a recording file as a pending asset before it is moved. It
+// returns nil when the file doesn't exist.
+func (s OmeService) createPendingVodAsset(ctx context.Context, recording *model.Recording, file RecordingFile, store storage.Storage) (*model.VodAsset, error) {
+	fileInfo, err := os.Stat(file.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := fileChecksum(file.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTime := time.Now()
+	asset := &model.VodAsset{
+		Id:          primitive.NewObjectID(),
+		StreamId:    recording.StreamId,
+		RecordingId: recording.Id,
+		Sequence:    file.Sequence,
+		SourcePath:  file.Path,
+		Status:      "pending",
+		Storage:     store.Name(),
+		StorageKey:  path.Join(recording.StreamId.Hex(), recording.Id.Hex(), filepath.Base(file.Path)),
+		Container:   strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Path)), "."),
+		DurationMs:  file.DurationMs,
+		SizeBytes:   fileInfo.Size(),
+		Checksum:    checksum,
+		CreatedAt:   currentTime,
+		UpdatedAt:   currentTime,
+	}
+	_, err = s.vodAssetCollection.InsertOne(ctx, asset)
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// storeVodAsset moves the file of a pending asset into store. When the source is gone, a previous
+// run already moved it and its location in store is returned, or an empty string if it is lost.
+func storeVodAsset(ctx context.Context, asset *model.VodAsset, store storage.Storage) (string, error) {
+	_, err := os.Stat(asset.SourcePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return store.Locate(ctx, asset.StorageKey)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	location, err := store.Store(ctx, asset.SourcePath, asset.StorageKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to store recording file: %w", err)
+	}
+	return location, nil
+}
+
+func (s OmeService) ListVodAssetsByStreamId(ctx context.Context, streamId primitive.ObjectID) ([]model.VodAsset, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "sequence", Value: 1}})
+	cursor, err := s.vodAssetCollection.Find(ctx, bson.M{"stream_id": streamId, "status": bson.M{"$ne": "pending"}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	assets := make([]model.VodAsset, 0)
+	if err := cursor.All(ctx, &assets); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+func (s OmeService) FindVodAssetById(ctx context.Context, id primitive.ObjectID) (*model.VodAsset, error) {
+	var result model.VodAsset
+
+	err := s.vodAssetCollection.FindOne(ctx, bson.M{"_id": id, "status": bson.M{"$ne": "pending"}}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s OmeService) findVodAssetBySource(ctx context.Context, recordingId primitive.ObjectID, sourcePath string) (*model.VodAsset, error) {
+	var result model.VodAsset
+
+	err := s.vodAssetCollection.FindOne(ctx, bson.M{"recording_id": recordingId, "source_path": sourcePath}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func fileChecksum(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
@@ -10,7 +11,10 @@ import (
 	"github.com/toufiq-austcse/go-api-boilerplate/docs"
 	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/controller"
 	indexRouter "github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/router"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/middleware"
 	"github.com/toufiq-austcse/go-api-boilerplate/internal/server"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/worker"
 	"time"
 )
 
@@ -25,15 +29,54 @@ func Run(configPath string) error {
 	}
 	err = container.Invoke(func(
 		controller *controller.OmeController,
+		omeService *service.OmeService,
 	) {
 		indexRouterGroup := apiServer.GinEngine.Group("")
-		indexRouter.Setup(indexRouterGroup, controller)
+		indexRouter.Setup(indexRouterGroup, controller, middleware.IdempotencyMiddleware(omeService))
 
 	})
 	if err != nil {
 		return err
 	}
 
+	err = container.Invoke(func(omeService *service.OmeService) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		err := omeService.EnsureIndexes(ctx)
+		if err != nil {
+			return err
+		}
+		err = omeService.EnsureMetricsCollection(ctx)
+		if err != nil {
+			return err
+		}
+		return omeService.SyncNodeServers(ctx)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = container.Invoke(func(
+		purgeWorker *worker.PurgeWorker,
+		pushReconciler *worker.PushReconciler,
+		pushRetryWorker *worker.PushRetryWorker,
+		nodeHealthMonitor *worker.NodeHealthMonitor,
+		metricsSampler *worker.MetricsSampler,
+		vodCatalogWorker *worker.VodCatalogWorker,
+		scheduler *worker.Scheduler,
+	) {
+		go purgeWorker.Start(context.Background())
+		go pushReconciler.Start(context.Background())
+		go pushRetryWorker.Start(context.Background())
+		go nodeHealthMonitor.Start(context.Background())
+		go metricsSampler.Start(context.Background())
+		go vodCatalogWorker.Start(context.Background())
+		go scheduler.Start(context.Background())
+	})
+	if err != nil {
+		return err
+	}
+
 	err = apiServer.Run()
 	if err != nil {
 		return err
@@ -52,8 +95,8 @@ func enableCors(engine *gin.Engine) {
 	corsConfig := cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "HEAD"},
-		AllowHeaders:     []string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Origin", "Cache-Control", "X-Requested-With", "Referer", "guest", "publicKey", "Access-Control-Allow-Origin"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Origin", "Cache-Control", "X-Requested-With", "Referer", "guest", "publicKey", "Access-Control-Allow-Origin", middleware.IdempotencyKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/api_response"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// IdempotencyMiddleware stores the first response for each Idempotency-Key and replays it for
+// later requests with the same key. Requests without the header pass through untouched.
+func IdempotencyMiddleware(omeService *service.OmeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		rawBody, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			errResponse := api_response.BuildErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request body",
+				err.Error(), "")
+			c.AbortWithStatusJSON(errResponse.Code, errResponse)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(rawBody))
+
+		hash := sha256.Sum256(append([]byte(c.Request.URL.RawQuery+"\n"), rawBody...))
+		requestHash := hex.EncodeToString(hash[:])
+		scopedKey := c.Request.Method + " " + c.Request.URL.Path + " " + key
+
+		record, reserved, err := omeService.ReserveIdempotencyKey(c, scopedKey, requestHash)
+		if err != nil {
+			fmt.Println("Error reserving idempotency key:", err)
+			errResponse := api_response.BuildErrorResponse(
+				http.StatusInternalServerError,
+				"Failed to check idempotency key",
+				err.Error(), "")
+			c.AbortWithStatusJSON(errResponse.Code, errResponse)
+			return
+		}
+
+		if !reserved {
+			if record.RequestHash != requestHash {
+				errResponse := api_response.BuildErrorResponse(
+					http.StatusUnprocessableEntity,
+					"Idempotency key reused",
+					"Idempotency-Key was already used with a different request", "")
+				c.AbortWithStatusJSON(errResponse.Code, errResponse)
+				return
+			}
+			if record.Status != "completed" {
+				errResponse := api_response.BuildErrorResponse(
+					http.StatusConflict,
+					"Request in progress",
+					"A request with this Idempotency-Key is still being processed", "")
+				c.AbortWithStatusJSON(errResponse.Code, errResponse)
+				return
+			}
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.ResponseCode, record.ContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+
+		// Server errors are not stored so the client can retry with the same key.
+		if writer.Status() >= http.StatusInternalServerError {
+			err = omeService.ReleaseIdempotencyKey(c, scopedKey)
+			if err != nil {
+				fmt.Println("Error releasing idempotency key:", err)
+			}
+			return
+		}
+		err = omeService.CompleteIdempotencyKey(c, scopedKey, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes())
+		if err != nil {
+			fmt.Println("Error storing idempotent response:", err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+)
+
+// MetricsSampler periodically records the OME stats of every live stream into the stream_metrics
+// time-series collection.
+type MetricsSampler struct {
+	omeService *service.OmeService
+}
+
+func NewMetricsSampler(omeService *service.OmeService) *MetricsSampler {
+	return &MetricsSampler{
+		omeService: omeService,
+	}
+}
+
+func (w *MetricsSampler) Start(ctx context.Context) {
+	ticker := time.NewTicker(config.AppConfig.METRICS_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *MetricsSampler) runOnce(ctx context.Context) {
+	streams, err := w.omeService.FindStreamsByStatus(ctx, "opening")
+	if err != nil {
+		fmt.Println("Error finding live streams:", err)
+		return
+	}
+
+	for i := range streams {
+		stream := &streams[i]
+		// Read OME directly, cached stats would be recorded twice when METRICS_INTERVAL is shorter
+		// than STATS_CACHE_TTL.
+		stats, err := w.omeService.FetchStreamStats(stream, time.Now())
+		if err != nil {
+			fmt.Println("Error fetching stream stats:", err)
+			continue
+		}
+		if !stats.Live {
+			continue
+		}
+
+		err = w.omeService.RecordStreamMetric(ctx, stream.Id, stats)
+		if err != nil {
+			fmt.Println("Error recording stream metric:", err)
+		}
+	}
+}
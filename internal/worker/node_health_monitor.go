@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+)
+
+// NodeHealthMonitor periodically probes the API of every registered OME node and records
+// its latency and health state.
+type NodeHealthMonitor struct {
+	omeService    *service.OmeService
+	omeHttpClient *http_clients.OmeHTTPClient
+}
+
+func NewNodeHealthMonitor(omeService *service.OmeService, omeHttpClient *http_clients.OmeHTTPClient) *NodeHealthMonitor {
+	return &NodeHealthMonitor{
+		omeService:    omeService,
+		omeHttpClient: omeHttpClient,
+	}
+}
+
+func (w *NodeHealthMonitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(config.AppConfig.NODE_HEALTH_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *NodeHealthMonitor) runOnce(ctx context.Context) {
+	// Pick up nodes registered through other instances of the service.
+	err := w.omeService.SyncNodeServers(ctx)
+	if err != nil {
+		fmt.Println("Error syncing OME nodes:", err)
+	}
+
+	nodes, err := w.omeService.ListNodes(ctx)
+	if err != nil {
+		fmt.Println("Error listing OME nodes:", err)
+		return
+	}
+	for i := range nodes {
+		node := &nodes[i]
+		startedAt := time.Now()
+		checkErr := w.omeHttpClient.CheckHealth(node.IpAddress)
+		latency := time.Since(startedAt)
+
+		updatedNode, err := w.omeService.RecordNodeHealthCheck(ctx, node, latency, checkErr)
+		if err != nil {
+			fmt.Println("Error recording node health:", err)
+			continue
+		}
+		if updatedNode != nil && updatedNode.HealthState != node.HealthState {
+			fmt.Printf("OME node %s (%s) is now %s\n", node.Name, node.IpAddress, updatedNode.HealthState)
+		}
+	}
+}
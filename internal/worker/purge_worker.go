@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/storage"
+)
+
+// PurgeWorker hard-deletes soft-deleted streams once the retention period has passed.
+type PurgeWorker struct {
+	omeService *service.OmeService
+	storage    storage.Storage
+}
+
+func NewPurgeWorker(omeService *service.OmeService, store storage.Storage) *PurgeWorker {
+	return &PurgeWorker{
+		omeService: omeService,
+		storage:    store,
+	}
+}
+
+func (w *PurgeWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(config.AppConfig.PURGE_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *PurgeWorker) runOnce(ctx context.Context) {
+	deletedBefore := time.Now().Add(-config.AppConfig.PURGE_RETENTION)
+	purged, err := w.omeService.PurgeDeletedStreams(ctx, deletedBefore, w.storage)
+	if err != nil {
+		fmt.Println("Error purging deleted streams:", err)
+		return
+	}
+	if purged > 0 {
+		fmt.Println("Purged deleted streams:", purged)
+	}
+}
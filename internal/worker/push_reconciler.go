@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// pendingPushGracePeriod keeps the reconciler away from pushes that are still being started.
+const pendingPushGracePeriod = 30 * time.Second
+
+// PushReconciler keeps the pushes collection in line with what OME is actually pushing.
+// Pushes OME no longer knows about, or lists as stopped or errored, are marked failed with their
+// last known OME state.
+type PushReconciler struct {
+	omeService    *service.OmeService
+	omeHttpClient *http_clients.OmeHTTPClient
+}
+
+func NewPushReconciler(omeService *service.OmeService, omeHttpClient *http_clients.OmeHTTPClient) *PushReconciler {
+	return &PushReconciler{
+		omeService:    omeService,
+		omeHttpClient: omeHttpClient,
+	}
+}
+
+func (w *PushReconciler) Start(ctx context.Context) {
+	ticker := time.NewTicker(config.AppConfig.RECONCILE_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *PushReconciler) runOnce(ctx context.Context) {
+	ips, err := w.omeService.ListStreamServerIps(ctx)
+	if err != nil {
+		fmt.Println("Error listing stream server ips:", err)
+		return
+	}
+	for _, ip := range ips {
+		err := w.reconcileServer(ctx, ip)
+		if err != nil {
+			fmt.Println("Error reconciling pushes on", ip, ":", err)
+		}
+	}
+}
+
+func (w *PushReconciler) reconcileServer(ctx context.Context, ip string) error {
+	now := time.Now()
+	pushes, err := w.omeService.FindPushesToReconcile(ctx, ip, now.Add(-pendingPushGracePeriod))
+	if err != nil {
+		return err
+	}
+
+	// OME lists pushes per application, so fetch the list of every app the pushes are sent from.
+	omePushesById := map[string]http_clients.PushResponseDetails{}
+	listedApps := map[string]bool{}
+	for i := range pushes {
+		app := w.omeService.GetPushApp(&pushes[i])
+		if listedApps[app] {
+			continue
+		}
+		omePushes, err := w.omeHttpClient.ListPushes(ip, app)
+		if err != nil {
+			return err
+		}
+		for _, omePush := range omePushes {
+			omePushesById[omePush.ID] = omePush
+		}
+		listedApps[app] = true
+	}
+
+	for _, push := range pushes {
+		omePush, found := omePushesById[push.Id.Hex()]
+		if !found {
+			fmt.Printf("Push %s vanished from OME on %s, last state: %s\n", push.Id.Hex(), ip, push.OmeState)
+			reason := fmt.Sprintf("push vanished from OME, last state: %s", push.OmeState)
+			err := w.omeService.MarkPushFailed(ctx, &push, reason, now)
+			if err != nil {
+				fmt.Println("Error marking push failed:", err)
+			}
+			continue
+		}
+
+		_, err := w.omeService.UpdatePushByID(ctx, push.Id, bson.M{
+			"reconciled_at":   now,
+			"ome_state":       omePush.State,
+			"sent_bytes":      omePush.SentBytes,
+			"total_sent_time": omePush.TotalSentTime,
+		})
+		if err != nil {
+			fmt.Println("Error updating reconciled push:", err)
+		}
+
+		// OME keeps pushes it gave up on in its list, they are as dead as vanished ones.
+		if omePush.State == "error" || omePush.State == "stopped" {
+			fmt.Printf("Push %s is %s on OME %s\n", push.Id.Hex(), omePush.State, ip)
+			err := w.omeService.MarkPushFailed(ctx, &push, "push "+omePush.State+" on OME", now)
+			if err != nil {
+				fmt.Println("Error marking push failed:", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/http_clients"
+)
+
+// PushRetryWorker re-issues failed pushes that have an auto-reconnect policy.
+type PushRetryWorker struct {
+	omeService    *service.OmeService
+	omeHttpClient *http_clients.OmeHTTPClient
+}
+
+func NewPushRetryWorker(omeService *service.OmeService, omeHttpClient *http_clients.OmeHTTPClient) *PushRetryWorker {
+	return &PushRetryWorker{
+		omeService:    omeService,
+		omeHttpClient: omeHttpClient,
+	}
+}
+
+func (w *PushRetryWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(config.AppConfig.PUSH_RETRY_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *PushRetryWorker) runOnce(ctx context.Context) {
+	pushes, err := w.omeService.FindPushesDueForRetry(ctx, time.Now())
+	if err != nil {
+		fmt.Println("Error finding pushes due for retry:", err)
+		return
+	}
+	for i := range pushes {
+		w.retryPush(ctx, &pushes[i])
+	}
+}
+
+func (w *PushRetryWorker) retryPush(ctx context.Context, push *model.Push) {
+	attemptErr := w.startPush(ctx, push)
+
+	updatedPush, err := w.omeService.RecordPushRetryAttempt(ctx, push, attemptErr)
+	if err != nil {
+		fmt.Println("Error recording push retry attempt:", err)
+		return
+	}
+	if updatedPush == nil && attemptErr == nil {
+		// The push was removed while we were restarting it, undo the restart.
+		err := w.omeHttpClient.StopPush(push.ServerIpAddress, w.omeService.GetPushApp(push), push.Id.Hex())
+		if err != nil {
+			fmt.Println("Error stopping removed push:", err)
+		}
+		return
+	}
+	if attemptErr != nil {
+		fmt.Printf("Push %s retry attempt %d failed: %v\n", push.Id.Hex(), push.RetryCount, attemptErr)
+	}
+}
+
+func (w *PushRetryWorker) startPush(ctx context.Context, push *model.Push) error {
+	stream, err := w.omeService.FindStreamById(ctx, push.StreamId)
+	if err != nil {
+		return err
+	}
+	if stream == nil {
+		return errors.New("stream does not exist")
+	}
+	if !stream.IsLive() {
+		return errors.New("stream is not live")
+	}
+	push.ServerIpAddress = stream.ServerIpAddress
+	push.App = w.omeService.GetStreamApp(stream)
+
+	err = w.omeService.EnsureServerAvailable(ctx, push.ServerIpAddress)
+	if err != nil {
+		return err
+	}
+
+	// OME may still hold the failed push under the same ID, clear it before starting again.
+	_ = w.omeHttpClient.StopPush(push.ServerIpAddress, push.App, push.Id.Hex())
+
+	_, err = w.omeHttpClient.StartPush(
+		push.ServerIpAddress,
+		push.App,
+		w.omeService.GetPushSourceStreamName(w.omeService.GetStreamIngestName(stream)),
+		push.Id.Hex(),
+		w.omeService.GetPushOptions(push))
+	return err
+}
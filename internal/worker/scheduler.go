@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/model"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+)
+
+// Scheduler starts the pushes of scheduled broadcasts when their window opens and the publisher
+// is live, and stops them when the window closes.
+type Scheduler struct {
+	omeService *service.OmeService
+}
+
+func NewScheduler(omeService *service.OmeService) *Scheduler {
+	return &Scheduler{
+		omeService: omeService,
+	}
+}
+
+func (w *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(config.AppConfig.SCHEDULER_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *Scheduler) runOnce(ctx context.Context) {
+	now := time.Now()
+
+	endingSchedules, err := w.omeService.FindSchedulesDueToEnd(ctx, now)
+	if err != nil {
+		fmt.Println("Error finding schedules due to end:", err)
+	}
+	for i := range endingSchedules {
+		schedule, err := w.omeService.EndSchedule(ctx, &endingSchedules[i], "completed")
+		if err != nil {
+			fmt.Println("Error ending schedule:", err)
+			continue
+		}
+		if schedule != nil {
+			fmt.Printf("Schedule %s of stream %s %s\n", schedule.Id.Hex(), schedule.StreamId.Hex(), schedule.Status)
+		}
+	}
+
+	startingSchedules, err := w.omeService.FindSchedulesDueToStart(ctx, now)
+	if err != nil {
+		fmt.Println("Error finding schedules due to start:", err)
+		return
+	}
+	for i := range startingSchedules {
+		w.startSchedule(ctx, &startingSchedules[i])
+	}
+}
+
+func (w *Scheduler) startSchedule(ctx context.Context, schedule *model.Schedule) {
+	stream, err := w.omeService.FindStreamById(ctx, schedule.StreamId)
+	if err != nil {
+		fmt.Println("Error finding stream by ID:", err)
+		return
+	}
+	if stream == nil {
+		_, err := w.omeService.EndSchedule(ctx, schedule, "cancelled")
+		if err != nil {
+			fmt.Println("Error cancelling schedule:", err)
+		}
+		return
+	}
+
+	// The publisher isn't live yet; the schedule starts once the opening webhook arrives.
+	startedSchedule, err := w.omeService.StartSchedule(ctx, stream, schedule)
+	if errors.Is(err, service.ErrStreamNotLive) {
+		return
+	}
+	if err != nil {
+		fmt.Println("Error starting schedule:", err)
+		return
+	}
+	if startedSchedule != nil {
+		fmt.Printf("Schedule %s of stream %s %s\n", startedSchedule.Id.Hex(), stream.Id.Hex(), startedSchedule.Status)
+	}
+}
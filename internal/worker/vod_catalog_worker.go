@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+	"github.com/toufiq-austcse/go-api-boilerplate/internal/api/ome/service"
+	"github.com/toufiq-austcse/go-api-boilerplate/pkg/storage"
+)
+
+// VodCatalogWorker polls OME for finished recordings and moves their files into VOD storage.
+type VodCatalogWorker struct {
+	omeService *service.OmeService
+	storage    storage.Storage
+}
+
+func NewVodCatalogWorker(omeService *service.OmeService, store storage.Storage) *VodCatalogWorker {
+	return &VodCatalogWorker{
+		omeService: omeService,
+		storage:    store,
+	}
+}
+
+func (w *VodCatalogWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(config.AppConfig.VOD_CATALOG_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *VodCatalogWorker) runOnce(ctx context.Context) {
+	err := w.omeService.SyncActiveRecordings(ctx)
+	if err != nil {
+		fmt.Println("Error syncing recordings:", err)
+	}
+
+	recordings, err := w.omeService.FindRecordingsToCatalog(ctx)
+	if err != nil {
+		fmt.Println("Error finding recordings to catalog:", err)
+		return
+	}
+	for i := range recordings {
+		err := w.omeService.CatalogRecording(ctx, &recordings[i], w.storage)
+		if err != nil {
+			fmt.Println("Error cataloging recording:", err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage keeps files in a directory on the local filesystem.
+type LocalStorage struct {
+	baseDir string
+}
+
+func NewLocalStorage(baseDir string) *LocalStorage {
+	return &LocalStorage{baseDir: baseDir}
+}
+
+func (s *LocalStorage) Name() string {
+	return "local"
+}
+
+func (s *LocalStorage) Locate(ctx context.Context, key string) (string, error) {
+	targetPath := filepath.Join(s.baseDir, filepath.FromSlash(key))
+	_, err := os.Stat(targetPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return targetPath, nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	err := os.Remove(filepath.Join(s.baseDir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) Store(ctx context.Context, sourcePath string, key string) (string, error) {
+	targetPath := filepath.Join(s.baseDir, filepath.FromSlash(key))
+	err := os.MkdirAll(filepath.Dir(targetPath), 0o755)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Rename(sourcePath, targetPath)
+	if err == nil {
+		return targetPath, nil
+	}
+
+	// Rename fails across filesystems, e.g. when OME records to a mounted volume.
+	err = copyFile(ctx, sourcePath, targetPath)
+	if err != nil {
+		return "", err
+	}
+	err = os.Remove(sourcePath)
+	if err != nil {
+		return "", err
+	}
+	return targetPath, nil
+}
+
+func copyFile(ctx context.Context, sourcePath string, targetPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.Create(targetPath)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(target, source)
+	if err != nil {
+		target.Close()
+		os.Remove(targetPath)
+		return err
+	}
+	return target.Close()
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/toufiq-austcse/go-api-boilerplate/config"
+)
+
+// Storage moves finished recording files into their long-term store.
+type Storage interface {
+	// Name identifies the backend, e.g. "local".
+	Name() string
+	// Store moves the file at sourcePath under key and returns its location in the store.
+	Store(ctx context.Context, sourcePath string, key string) (string, error)
+	// Locate returns the location of the file stored under key, or an empty string when there is none.
+	Locate(ctx context.Context, key string) (string, error)
+	// Delete removes the file stored under key. Deleting a missing file is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the backend selected by VOD_STORAGE.
+func New() (Storage, error) {
+	switch config.AppConfig.VOD_STORAGE {
+	case "local":
+		return NewLocalStorage(config.AppConfig.VOD_STORAGE_DIR), nil
+	default:
+		return nil, fmt.Errorf("unknown vod storage %q", config.AppConfig.VOD_STORAGE)
+	}
+}